@@ -0,0 +1,169 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/diff"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+)
+
+// Event is the outcome of one scrape, evaluated against every rule
+type Event struct {
+	Package  string
+	App      *parser.App   // nil when NotFound
+	Changes  []diff.Change // changes since the previous snapshot
+	NotFound bool
+	At       time.Time
+}
+
+// Engine evaluates rules after each scrape and delivers matching webhooks
+type Engine struct {
+	store    *Store
+	notifier *Notifier
+}
+
+// NewEngine wires the rule store to a webhook notifier
+func NewEngine(store *Store, notifier *Notifier) *Engine {
+	return &Engine{store: store, notifier: notifier}
+}
+
+// Process evaluates ev against every rule for its package and delivers the
+// matches. Deliveries retry with backoff, so callers usually run this in a
+// goroutine.
+func (e *Engine) Process(ctx context.Context, ev Event) {
+	log := logging.From(ctx).With("package", ev.Package)
+
+	rules, err := e.store.Rules()
+	if err != nil {
+		log.Error("loading alert rules failed", "error", err)
+		return
+	}
+
+	for _, rule := range rules {
+		if !rule.matchesPackage(ev.Package) {
+			continue
+		}
+
+		reason, fire := e.evaluate(rule, ev)
+		if !fire {
+			continue
+		}
+		log.Info("alert fired", "rule_id", rule.ID, "rule", rule.Name, "reason", reason)
+
+		e.notifier.Deliver(ctx, rule, Payload{
+			RuleID:   rule.ID,
+			RuleName: rule.Name,
+			Type:     rule.Type,
+			Package:  ev.Package,
+			Reason:   reason,
+			App:      ev.App,
+			Changes:  ev.Changes,
+			FiredAt:  ev.At.UTC(),
+		})
+	}
+}
+
+// evaluate returns why the rule fires, or fire=false
+func (e *Engine) evaluate(rule Rule, ev Event) (string, bool) {
+	switch rule.Type {
+
+	case TypeNotFound:
+		return e.edge(rule, ev.Package, ev.NotFound, "app is no longer available on Google Play")
+
+	case TypeThreshold:
+		if ev.NotFound || ev.App == nil {
+			return "", false
+		}
+		actual := FieldValue(ev.App, rule.Field)
+		match := Compare(actual, rule.Op, rule.Value)
+		return e.edge(rule, ev.Package, match,
+			fmt.Sprintf("%s is %s (rule: %s %s %s)", rule.Field, actual, rule.Field, rule.Op, rule.Value))
+
+	case TypeVersionChange:
+		for _, ch := range ev.Changes {
+			if ch.Field == "version" && ch.Old != "" && ch.Old != "N.A" {
+				return fmt.Sprintf("version changed from %s to %s", ch.Old, ch.New), true
+			}
+		}
+
+	case TypeFieldChange:
+		for _, ch := range ev.Changes {
+			if ch.Field == rule.Field {
+				return fmt.Sprintf("%s changed", ch.Label), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// edge fires only on a false -> true transition of an ongoing condition
+func (e *Engine) edge(rule Rule, pkg string, match bool, reason string) (string, bool) {
+	was, err := e.store.setFiring(rule.ID, pkg, match)
+	if err != nil {
+		slog.Error("saving alert state failed", "rule_id", rule.ID, "package", pkg, "error", err)
+		return "", false
+	}
+	return reason, match && !was
+}
+
+// FieldValue returns a parser.App field by its json name, formatted as text
+func FieldValue(app *parser.App, field string) string {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return ""
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return ""
+	}
+	v, ok := m[field]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// Compare applies op to actual and expected, numerically when both parse
+// as numbers ("1.2M", "50M+", "4.3"). Otherwise only == and != match, as
+// text: an ordering needs two numbers, so an app without a rating ("") is
+// not "< 4.2".
+func Compare(actual, op, expected string) bool {
+	if op == "contains" {
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(expected))
+	}
+
+	a, ok1 := parser.ParseNumber(actual)
+	b, ok2 := parser.ParseNumber(expected)
+	if ok1 && ok2 {
+		switch op {
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		}
+		return false
+	}
+
+	switch op {
+	case "==":
+		return actual == expected
+	case "!=":
+		return actual != expected
+	}
+	return false
+}
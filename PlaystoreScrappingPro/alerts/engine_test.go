@@ -0,0 +1,120 @@
+package alerts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+)
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		actual, op, expected string
+		want                 bool
+	}{
+		{"4.1", "<", "4.2", true},
+		{"4.2", "<=", "4.2", true},
+		{"1.2M", ">", "900K", true},
+		{"50M+", ">=", "50,000,000", true},
+		{"4.5", "==", "4.50", true},
+		{"4.5", "!=", "4.5", false},
+		{"", "<", "4.2", false}, // no rating is not a low rating
+		{"", ">", "4.2", false},
+		{"N.A", "<=", "4.2", false},
+		{"abc", "<", "abd", false}, // text has no order
+		{"2.1", "==", "2.1", true},
+		{"v2", "!=", "v3", true},
+		{"Example Messenger", "contains", "messenger", true},
+		{"4.1", "~", "4.2", false},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.actual, tt.op, tt.expected); got != tt.want {
+			t.Errorf("Compare(%q, %q, %q) = %v, want %v", tt.actual, tt.op, tt.expected, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Rule{Package: "com.example.app", Type: TypeThreshold, Field: "rating", Op: "<", Value: "4.2", WebhookURL: "https://example.com/hook"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid rule: %v", err)
+	}
+
+	for name, change := range map[string]func(r *Rule){
+		"unknown field":        func(r *Rule) { r.Field = "ratting" },
+		"diagnostics field":    func(r *Rule) { r.Field = "Diagnostics" },
+		"unknown change field": func(r *Rule) { r.Type, r.Field = TypeFieldChange, "versions" },
+		"bad op":               func(r *Rule) { r.Op = "=~" },
+		"no package":           func(r *Rule) { r.Package = "" },
+		"relative webhook":     func(r *Rule) { r.WebhookURL = "/hook" },
+	} {
+		r := valid
+		change(&r)
+		if err := r.Validate(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+
+	change := valid
+	change.Type, change.Field = TypeFieldChange, "InAppPurchase"
+	if err := change.Validate(); err != nil {
+		t.Errorf("field_change on InAppPurchase: %v", err)
+	}
+}
+
+func TestEngineThreshold(t *testing.T) {
+	var hooks atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hooks.Add(1)
+	}))
+	defer srv.Close()
+
+	store := openStore(t)
+	rule, err := store.AddRule(Rule{Name: "low rating", Package: "*", Type: TypeThreshold, Field: "rating", Op: "<", Value: "4.2", WebhookURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(store, NewNotifier(store))
+
+	// fires once when the condition becomes true, again after it re-armed
+	for i, step := range []struct {
+		rating string
+		hooks  int32
+	}{
+		{"", 0}, // no ratings yet
+		{"4.1", 1},
+		{"4.0", 1},
+		{"4.5", 1},
+		{"3.9", 2},
+	} {
+		engine.Process(context.Background(), Event{Package: "com.example.app", App: &parser.App{Rating: step.rating}, At: time.Now()})
+		if got := hooks.Load(); got != step.hooks {
+			t.Errorf("step %d (rating %q): %d webhooks, want %d", i, step.rating, got, step.hooks)
+		}
+	}
+
+	deliveries, err := store.Deliveries(rule.ID, 10)
+	if err != nil || len(deliveries) != 2 || !deliveries[0].Success {
+		t.Errorf("deliveries = %+v, %v", deliveries, err)
+	}
+}
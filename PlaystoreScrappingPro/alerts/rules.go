@@ -0,0 +1,227 @@
+package alerts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Layout:
+//
+//	"alert-rules"      rule id          -> JSON Rule
+//	"alert-state"      rule id/package  -> "1" while a threshold/not-found rule is firing
+//	"alert-deliveries" big-endian nanos -> JSON Delivery (the delivery log)
+const (
+	rulesBucket      = "alert-rules"
+	stateBucket      = "alert-state"
+	deliveriesBucket = "alert-deliveries"
+)
+
+// Rule types
+const (
+	TypeThreshold     = "threshold"      // Field Op Value, e.g. rating < 4.2
+	TypeVersionChange = "version_change" // a new version was shipped
+	TypeFieldChange   = "field_change"   // Field changed between two scrapes
+	TypeNotFound      = "not_found"      // the app disappeared from the store
+)
+
+// Rule fires a webhook when its condition matches a scrape of Package
+// ("*" matches every package). Threshold and not-found rules are edge
+// triggered: they fire once when the condition becomes true and re-arm when
+// it becomes false again.
+type Rule struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Package    string    `json:"package"`
+	Type       string    `json:"type"`
+	Field      string    `json:"field,omitempty"` // json name of a parser.App field
+	Op         string    `json:"op,omitempty"`    // < <= > >= == != contains
+	Value      string    `json:"value,omitempty"`
+	WebhookURL string    `json:"webhookUrl"`
+	Secret     string    `json:"secret,omitempty"` // HMAC key for X-Playstore-Signature (write-only, see Redacted)
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Redacted returns r without its secret, as API responses show it
+func (r Rule) Redacted() Rule {
+	r.Secret = ""
+	return r
+}
+
+var validOps = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true, "contains": true}
+
+// appFields are the json names of parser.App's fields, the fields a rule
+// can watch
+var appFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(parser.App{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// Validate checks the rule is complete and well-formed
+func (r *Rule) Validate() error {
+	if r.Package == "" {
+		return fmt.Errorf("package is required (use * for every package)")
+	}
+
+	switch r.Type {
+	case TypeThreshold:
+		if r.Field == "" || r.Value == "" {
+			return fmt.Errorf("threshold rules need field, op and value")
+		}
+		if !validOps[r.Op] {
+			return fmt.Errorf("op must be one of < <= > >= == != contains")
+		}
+		if !appFields[r.Field] {
+			return fmt.Errorf("unknown field %q (use an app field's JSON name, e.g. rating)", r.Field)
+		}
+	case TypeFieldChange:
+		if r.Field == "" {
+			return fmt.Errorf("field_change rules need a field")
+		}
+		if !appFields[r.Field] {
+			return fmt.Errorf("unknown field %q (use an app field's JSON name, e.g. version)", r.Field)
+		}
+	case TypeVersionChange, TypeNotFound:
+	default:
+		return fmt.Errorf("type must be threshold, version_change, field_change or not_found")
+	}
+
+	u, err := url.Parse(r.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhookUrl must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+func (r *Rule) matchesPackage(pkg string) bool {
+	return r.Package == "*" || strings.EqualFold(r.Package, pkg)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// STORE
+///////////////////////////////////////////////////////////////////////////////
+
+// Store persists rules, firing state and the delivery log
+type Store struct {
+	db *storage.DB
+}
+
+// NewStore prepares the alert buckets in db
+func NewStore(db *storage.DB) (*Store, error) {
+	if err := db.EnsureBuckets(rulesBucket, stateBucket, deliveriesBucket); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// AddRule validates and saves a new rule, assigning its id
+func (s *Store) AddRule(r Rule) (Rule, error) {
+	if err := r.Validate(); err != nil {
+		return r, err
+	}
+	r.ID = newID()
+	r.CreatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return r, err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(rulesBucket)).Put([]byte(r.ID), data)
+	})
+	return r, err
+}
+
+// Rule returns one rule (nil if it doesn't exist)
+func (s *Store) Rule(id string) (*Rule, error) {
+	var rule *Rule
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(rulesBucket)).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		rule = &Rule{}
+		return json.Unmarshal(v, rule)
+	})
+	return rule, err
+}
+
+// DeleteRule removes a rule and its firing state, reporting whether it existed
+func (s *Store) DeleteRule(id string) (bool, error) {
+	found := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(rulesBucket))
+		found = b.Get([]byte(id)) != nil
+		if err := b.Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		state := tx.Bucket([]byte(stateBucket))
+		c := state.Cursor()
+		prefix := []byte(id + "/")
+		for k, _ := c.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, _ = c.Next() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Rules returns every rule, oldest first
+func (s *Store) Rules() ([]Rule, error) {
+	rules := []Rule{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(rulesBucket)).ForEach(func(k, v []byte) error {
+			var r Rule
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("corrupt rule %s: %v", k, err)
+			}
+			rules = append(rules, r)
+			return nil
+		})
+	})
+	sort.Slice(rules, func(i, j int) bool { return rules[i].CreatedAt.Before(rules[j].CreatedAt) })
+	return rules, err
+}
+
+// setFiring records whether rule is currently firing for pkg and returns the
+// previous state
+func (s *Store) setFiring(ruleID, pkg string, firing bool) (bool, error) {
+	was := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(stateBucket))
+		key := []byte(ruleID + "/" + pkg)
+		was = b.Get(key) != nil
+		if firing {
+			return b.Put(key, []byte("1"))
+		}
+		return b.Delete(key)
+	})
+	return was, err
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
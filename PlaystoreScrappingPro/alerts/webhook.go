@@ -0,0 +1,201 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/diff"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Webhook headers. The signature is "sha256=" + hex(HMAC-SHA256(secret,
+// timestamp + "." + body)), so receivers can reject replayed requests.
+const (
+	SignatureHeader = "X-Playstore-Signature"
+	TimestampHeader = "X-Playstore-Timestamp"
+	DeliveryHeader  = "X-Playstore-Delivery"
+)
+
+// Payload is the JSON body of every webhook
+type Payload struct {
+	DeliveryID string        `json:"deliveryId"`
+	RuleID     string        `json:"ruleId"`
+	RuleName   string        `json:"ruleName"`
+	Type       string        `json:"type"`
+	Package    string        `json:"package"`
+	Reason     string        `json:"reason"`
+	App        *parser.App   `json:"app,omitempty"`
+	Changes    []diff.Change `json:"changes,omitempty"`
+	FiredAt    time.Time     `json:"firedAt"`
+	Test       bool          `json:"test,omitempty"`
+}
+
+// Delivery is one entry of the delivery log
+type Delivery struct {
+	ID         string    `json:"id"`
+	RuleID     string    `json:"ruleId"`
+	Package    string    `json:"package"`
+	URL        string    `json:"url"`
+	Reason     string    `json:"reason"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	At         time.Time `json:"at"`
+}
+
+// Sign computes the signature header value for body
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received signature header in constant time
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// NOTIFIER — POST with retry + delivery log
+///////////////////////////////////////////////////////////////////////////////
+
+// Notifier delivers webhooks, retrying failed attempts with exponential backoff
+type Notifier struct {
+	store   *Store
+	client  *http.Client
+	Retries int           // attempts after the first one
+	Backoff time.Duration // wait before the first retry, doubled each time
+}
+
+// NewNotifier returns a notifier logging deliveries to store
+func NewNotifier(store *Store) *Notifier {
+	return &Notifier{
+		store:   store,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		Retries: 3,
+		Backoff: time.Second,
+	}
+}
+
+// Deliver sends p to rule's webhook and records the outcome in the log
+func (n *Notifier) Deliver(ctx context.Context, rule Rule, p Payload) Delivery {
+	p.DeliveryID = newID()
+
+	d := Delivery{
+		ID:      p.DeliveryID,
+		RuleID:  rule.ID,
+		Package: p.Package,
+		URL:     rule.WebhookURL,
+		Reason:  p.Reason,
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		d.Error = err.Error()
+		d.At = time.Now().UTC()
+		n.log(d)
+		return d
+	}
+
+	wait := n.Backoff
+	for attempt := 0; attempt <= n.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+		d.Attempts++
+
+		d.StatusCode, err = n.post(rule, p.DeliveryID, body)
+		if err == nil {
+			d.Success = true
+			d.Error = ""
+			break
+		}
+		d.Error = err.Error()
+		logging.From(ctx).Warn("webhook attempt failed",
+			"rule_id", rule.ID, "delivery_id", d.ID, "attempt", d.Attempts, "status", d.StatusCode, "error", err)
+	}
+
+	d.At = time.Now().UTC()
+	n.log(d)
+	return d
+}
+
+func (n *Notifier) post(rule Rule, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, rule.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "playstore-scraper-webhooks/1.0")
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	if rule.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(rule.Secret, ts, body))
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook returned status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+func (n *Notifier) log(d Delivery) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(d.At.UnixNano()))
+
+	err = n.store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(deliveriesBucket)).Put(key, data)
+	})
+	if err != nil {
+		slog.Error("saving webhook delivery failed", "delivery_id", d.ID, "error", err)
+	}
+}
+
+// Deliveries returns the most recent deliveries (newest first), optionally
+// only those of one rule
+func (s *Store) Deliveries(ruleID string, limit int) ([]Delivery, error) {
+	out := []Delivery{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(deliveriesBucket)).Cursor()
+		for k, v := c.Last(); k != nil && len(out) < limit; k, v = c.Prev() {
+			var d Delivery
+			if err := json.Unmarshal(v, &d); err != nil {
+				return fmt.Errorf("corrupt delivery %x: %v", k, err)
+			}
+			if ruleID == "" || d.RuleID == ruleID {
+				out = append(out, d)
+			}
+		}
+		return nil
+	})
+	return out, err
+}
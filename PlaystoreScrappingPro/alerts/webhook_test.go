@@ -0,0 +1,70 @@
+package alerts
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"ruleId":"r1"}`)
+	sig := Sign("s3cret", "1700000000", body)
+
+	if !Verify("s3cret", "1700000000", body, sig) {
+		t.Fatal("Verify rejects its own signature")
+	}
+	if Verify("other", "1700000000", body, sig) {
+		t.Error("Verify accepts another secret")
+	}
+	if Verify("s3cret", "1700000001", body, sig) {
+		t.Error("Verify accepts a replay with another timestamp")
+	}
+	if Verify("s3cret", "1700000000", []byte(`{"ruleId":"r2"}`), sig) {
+		t.Error("Verify accepts another body")
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	var attempts atomic.Int32
+	verified := make(chan bool, 20)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified <- Verify("s3cret", r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader))
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	store := openStore(t)
+	n := NewNotifier(store)
+	n.Backoff = time.Millisecond
+	rule := Rule{ID: "r1", WebhookURL: srv.URL, Secret: "s3cret"}
+
+	d := n.Deliver(context.Background(), rule, Payload{RuleID: "r1", Package: "com.example.app", Reason: "test"})
+	if !d.Success || d.Attempts != 3 || d.StatusCode != http.StatusOK || d.Error != "" {
+		t.Errorf("delivery = %+v, want success on the third attempt", d)
+	}
+	for len(verified) > 0 {
+		if !<-verified {
+			t.Error("webhook signature does not verify")
+		}
+	}
+
+	// gives up after Retries and logs the failure
+	attempts.Store(-10)
+	n.Retries = 1
+	d = n.Deliver(context.Background(), rule, Payload{RuleID: "r1", Package: "com.example.app", Reason: "test"})
+	if d.Success || d.Attempts != 2 || d.StatusCode != http.StatusServiceUnavailable || d.Error == "" {
+		t.Errorf("failed delivery = %+v", d)
+	}
+
+	log, err := store.Deliveries("r1", 10)
+	if err != nil || len(log) != 2 || log[0].Success || !log[1].Success {
+		t.Errorf("delivery log = %+v, %v", log, err)
+	}
+}
@@ -0,0 +1,813 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/alerts"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/apikeys"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/compare"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/gql"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/history"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/jobs"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/output"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	"github.com/gin-gonic/gin"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// JSON API
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	MaxBatchSize     = 50
+	BatchConcurrency = 4
+	MaxCompare       = 10
+	MaxJobSize       = 10000
+	MaxSearchQuery   = 100 // characters
+)
+
+// BatchResult is one entry of a batch response
+type BatchResult struct {
+	Package string      `json:"package"`
+	App     *parser.App `json:"app,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// apiStatus maps a fetchApp error to an HTTP status code
+func apiStatus(err error) int {
+	if errors.Is(err, errUpstream) {
+		return http.StatusBadGateway
+	}
+	return http.StatusNotFound // scraper.ErrNotFound or parser "app not found"
+}
+
+// requestFormat validates ?format= (json by default)
+func requestFormat(c *gin.Context) (string, bool) {
+	format := strings.ToLower(c.DefaultQuery("format", output.FormatJSON))
+	if format != output.FormatJSON && !output.IsExportFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, csv or xlsx"})
+		return "", false
+	}
+	return format, true
+}
+
+// -----------------------------------------------------------------------
+// GET /api/app-info?package=com.whatsapp[&format=csv|xlsx][&debug=1]
+// [&iconSize=512][&imageSize=1080x1920][&country=IN]
+// -----------------------------------------------------------------------
+func apiAppInfo(c *gin.Context) {
+
+	format, ok := requestFormat(c)
+	if !ok {
+		return
+	}
+
+	iconSize, err := parser.ParseImageSize(c.Query("iconSize"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "iconSize: " + err.Error()})
+		return
+	}
+	imageSize, err := parser.ParseImageSize(c.Query("imageSize"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "imageSize: " + err.Error()})
+		return
+	}
+
+	pkg, err := sanitizePackage(c.Query("package"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	country, err := sanitizeCountry(c.Query("country"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	app, err := fetchAppIn(c.Request.Context(), pkg, country)
+	if err != nil {
+		c.JSON(apiStatus(err), gin.H{"package": pkg, "error": err.Error()})
+		return
+	}
+	app = app.WithImageSizes(iconSize, imageSize)
+
+	if output.IsExportFormat(format) {
+		output.SendExport(c, format, pkg, []output.ExportRow{{Package: pkg, App: app}})
+		return
+	}
+
+	if output.DebugRequested(c) {
+		c.JSON(http.StatusOK, appDiagnostics{App: app, Diagnostics: app.Diagnostics})
+		return
+	}
+	c.JSON(http.StatusOK, app)
+}
+
+// apiReviews handles GET /api/reviews/:package?limit=20
+func apiReviews(c *gin.Context) {
+	pkg, err := sanitizePackage(c.Param("package"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > gql.MaxReviews {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", gql.MaxReviews)})
+		return
+	}
+
+	reviews, err := fetchReviews(c.Request.Context(), pkg)
+	if err != nil {
+		c.JSON(apiStatus(err), gin.H{"package": pkg, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"package": pkg, "reviews": reviews[:min(limit, len(reviews))]})
+}
+
+// searchQuery validates a search query (trimmed, 1..MaxSearchQuery characters)
+func searchQuery(q string) (string, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return "", fmt.Errorf("search query is required")
+	}
+	if len([]rune(q)) > MaxSearchQuery {
+		return "", fmt.Errorf("search query too long (max %d characters)", MaxSearchQuery)
+	}
+	return q, nil
+}
+
+// apiSearch handles GET /api/search?q=messenger
+func apiSearch(c *gin.Context) {
+	q, err := searchQuery(c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := fetchSearch(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(apiStatus(err), gin.H{"query": q, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"query": q, "results": results})
+}
+
+// appDiagnostics is an app plus its extraction report (?debug=1)
+type appDiagnostics struct {
+	*parser.App
+	Diagnostics *parser.Diagnostics `json:"diagnostics"`
+}
+
+// -----------------------------------------------------------------------
+// GET  /api/batch?packages=com.a,com.b[&format=csv|xlsx]
+// POST /api/batch  {"packages": ["com.a", "com.b"]}
+// -----------------------------------------------------------------------
+func apiBatch(c *gin.Context) {
+
+	format, ok := requestFormat(c)
+	if !ok {
+		return
+	}
+
+	pkgs, err := batchPackages(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !chargePackages(c, pkgs, nil) {
+		return
+	}
+
+	results := runBatch(c.Request.Context(), pkgs)
+
+	if output.IsExportFormat(format) {
+		rows := make([]output.ExportRow, len(results))
+		for i, r := range results {
+			rows[i] = output.ExportRow{Package: r.Package, App: r.App, Error: r.Error}
+		}
+		output.SendExport(c, format, "batch", rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// batchPackages reads up to MaxBatchSize package names from the query string
+// and/or JSON body
+func batchPackages(c *gin.Context) ([]string, error) {
+	pkgs, err := requestPackages(c, MaxBatchSize)
+	if err != nil && len(pkgs) > MaxBatchSize {
+		return nil, fmt.Errorf("%v; submit larger batches to POST /api/jobs", err)
+	}
+	return pkgs, err
+}
+
+// requestPackages reads up to max distinct, sanitized package names from the
+// query string and/or JSON body (a POST may leave the body empty)
+func requestPackages(c *gin.Context, max int) ([]string, error) {
+	var raw []string
+	for _, v := range c.QueryArray("packages") {
+		raw = append(raw, strings.Split(v, ",")...)
+	}
+
+	if c.Request.Method == http.MethodPost {
+		var body struct {
+			Packages []string `json:"packages"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid JSON body: %v", err)
+		}
+		raw = append(raw, body.Packages...)
+	}
+
+	seen := make(map[string]bool)
+	var pkgs []string
+	for _, r := range raw {
+		if strings.TrimSpace(r) == "" {
+			continue
+		}
+		pkg, err := sanitizePackage(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", strings.TrimSpace(r), err)
+		}
+		if !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("at least one package name is required")
+	}
+	if len(pkgs) > max {
+		return pkgs, fmt.Errorf("too many packages (max %d)", max)
+	}
+	return pkgs, nil
+}
+
+// runBatch fetches every package with bounded concurrency, keeping input order
+func runBatch(ctx context.Context, pkgs []string) []BatchResult {
+	results := make([]BatchResult, len(pkgs))
+	runBatchEach(ctx, pkgs, func(i int, r BatchResult) {
+		results[i] = r
+	})
+	return results
+}
+
+// runBatchEach fetches every package with bounded concurrency, calling done
+// (from the fetching goroutine) with each result as soon as it is known
+func runBatchEach(ctx context.Context, pkgs []string, done func(i int, r BatchResult)) {
+	sem := make(chan struct{}, BatchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, pkg := range pkgs {
+		wg.Add(1)
+		go func(i int, pkg string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r := BatchResult{Package: pkg}
+			app, err := fetchApp(ctx, pkg)
+			if err != nil {
+				r.Error = err.Error()
+			} else {
+				r.App = app
+			}
+			done(i, r)
+		}(i, pkg)
+	}
+
+	wg.Wait()
+}
+
+// -----------------------------------------------------------------------
+// GET /api/compare?packages=com.a,com.b[,...]
+// -----------------------------------------------------------------------
+func apiCompare(c *gin.Context) {
+	pkgs, err := comparePackages(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !chargePackages(c, pkgs, nil) {
+		return
+	}
+	c.JSON(http.StatusOK, buildComparison(c.Request.Context(), pkgs))
+}
+
+// comparePackages reads the 2..MaxCompare packages to compare
+func comparePackages(c *gin.Context) ([]string, error) {
+	pkgs, err := batchPackages(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) < 2 {
+		return nil, fmt.Errorf("at least two package names are required to compare")
+	}
+	if len(pkgs) > MaxCompare {
+		return nil, fmt.Errorf("too many packages to compare (max %d)", MaxCompare)
+	}
+	return pkgs, nil
+}
+
+// buildComparison fetches pkgs (cache first) and aligns them
+func buildComparison(ctx context.Context, pkgs []string) compare.Table {
+	results := runBatch(ctx, pkgs)
+	columns := make([]compare.Column, len(results))
+	for i, r := range results {
+		columns[i] = compare.Column{Package: r.Package, App: r.App, Error: r.Error}
+	}
+	return compare.Build(columns)
+}
+
+// chargePackages takes the packages of a multi-package request from the
+// caller's daily quota. The guard already took one request, which covers the
+// first package; when the rest don't fit, the request is answered with 429
+// (through reject, nil = JSON) and nothing is fetched.
+func chargePackages(c *gin.Context, pkgs []string, reject apikeys.RejectFunc) bool {
+	return apiGuard.ChargeRequest(c, len(pkgs)-1, reject)
+}
+
+// chargeContext is the GraphQL side of chargePackages, for the key the guard
+// put in ctx
+func chargeContext(ctx context.Context, n int) error {
+	if rej := apiGuard.Charge(ctx, n); rej != nil {
+		return errors.New(rej.Message)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------
+// GET /api/history/:package?from=2025-01-01&to=2025-02-01&interval=daily
+// -----------------------------------------------------------------------
+func apiHistory(c *gin.Context) {
+
+	pkg, err := sanitizePackage(c.Param("package"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interval, err := history.ParseInterval(c.Query("interval"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := dateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	points, err := historyStore.Series(pkg, from, to, interval)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"package":  pkg,
+		"from":     from,
+		"to":       to,
+		"interval": interval,
+		"points":   points,
+	})
+}
+
+// -----------------------------------------------------------------------
+// GET /api/history/:package/changes?from=2025-01-01&to=2025-02-01
+// -----------------------------------------------------------------------
+func apiChanges(c *gin.Context) {
+
+	pkg, err := sanitizePackage(c.Param("package"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := dateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snaps, err := historyStore.Changes(pkg, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"package":   pkg,
+		"from":      from,
+		"to":        to,
+		"snapshots": snaps,
+	})
+}
+
+// dateRange reads ?from= and ?to= (see history.ParseRange)
+func dateRange(c *gin.Context) (time.Time, time.Time, error) {
+	return history.ParseRange(c.Query("from"), c.Query("to"), 30)
+}
+
+// apiWatchlist handles GET /api/watchlist
+func apiWatchlist(c *gin.Context) {
+	entries, err := watchStore.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"packages": entries})
+}
+
+// apiWatchlistAdd handles POST /api/watchlist {"package": "com.whatsapp"}
+func apiWatchlistAdd(c *gin.Context) {
+	var body struct {
+		Package string `json:"package"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid JSON body: %v", err)})
+		return
+	}
+
+	pkg, err := sanitizePackage(body.Package)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := watchStore.Add(pkg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// apiWatchlistRemove handles DELETE /api/watchlist/:package
+func apiWatchlistRemove(c *gin.Context) {
+	pkg, err := sanitizePackage(c.Param("package"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	found, err := watchStore.Remove(pkg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "package is not on the watchlist"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// apiAlertRules handles GET /api/alerts/rules
+func apiAlertRules(c *gin.Context) {
+	rules, err := alertStore.Rules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range rules {
+		rules[i] = rules[i].Redacted()
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// apiAlertRuleAdd handles POST /api/alerts/rules with a JSON alerts.Rule, e.g.
+//
+//	{"name": "rating drop", "package": "com.whatsapp", "type": "threshold",
+//	 "field": "rating", "op": "<", "value": "4.2",
+//	 "webhookUrl": "http://localhost:9000/hook", "secret": "s3cret"}
+func apiAlertRuleAdd(c *gin.Context) {
+	var rule alerts.Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid JSON body: %v", err)})
+		return
+	}
+
+	if rule.Package != "*" {
+		pkg, err := sanitizePackage(rule.Package)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rule.Package = pkg
+	}
+
+	rule, err := alertStore.AddRule(rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, rule.Redacted())
+}
+
+// apiAlertRuleDelete handles DELETE /api/alerts/rules/:id
+func apiAlertRuleDelete(c *gin.Context) {
+	found, err := alertStore.DeleteRule(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// apiAlertRuleTest handles POST /api/alerts/rules/:id/test — sends a test
+// webhook synchronously and returns the delivery record
+func apiAlertRuleTest(c *gin.Context) {
+	rule, err := alertStore.Rule(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+		return
+	}
+
+	d := alertNotifier.Deliver(c.Request.Context(), *rule, alerts.Payload{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		Type:     rule.Type,
+		Package:  rule.Package,
+		Reason:   "test delivery",
+		FiredAt:  time.Now().UTC(),
+		Test:     true,
+	})
+	c.JSON(http.StatusOK, d)
+}
+
+// apiAlertDeliveries handles GET /api/alerts/deliveries?rule=<id>&limit=50
+func apiAlertDeliveries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	deliveries, err := alertStore.Deliveries(c.Query("rule"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// apiParserRules handles GET /api/parser/rules (the selector rules in use)
+func apiParserRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"path": rulesPath, "rules": parser.CurrentRules()})
+}
+
+// apiParserRulesReload handles POST /api/parser/rules/reload
+func apiParserRulesReload(c *gin.Context) {
+	rules, err := reloadRules()
+	if errors.Is(err, errNoRulesFile) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"path": rulesPath, "version": rules.Version})
+}
+
+// apiAdminKeys handles GET /admin/keys (every key with today's usage)
+func apiAdminKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"keys": apiKeys.List()})
+}
+
+// apiAdminKeyCreate handles POST /admin/keys
+//
+//	{"name": "ci", "rateLimit": 2, "burst": 5, "dailyQuota": 1000, "admin": false}
+//
+// Omitted limits take the server defaults; "dailyQuota": 0 or "rateLimit": 0
+// means unlimited. The secret is only returned in this response.
+func apiAdminKeyCreate(c *gin.Context) {
+	var spec apikeys.Spec
+	if err := c.ShouldBindJSON(&spec); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON body"})
+		return
+	}
+
+	key, secret, err := apiKeys.Create(spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"key": apiKeys.Status(key.ID), "secret": secret})
+}
+
+// apiAdminKey handles GET /admin/keys/:id (the key and its daily usage)
+func apiAdminKey(c *gin.Context) {
+	status := apiKeys.Status(c.Param("id"))
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no such key"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// apiAdminKeyRevoke handles DELETE /admin/keys/:id
+func apiAdminKeyRevoke(c *gin.Context) {
+	found, err := apiKeys.Revoke(c.Param("id"))
+	switch {
+	case !found:
+		c.JSON(http.StatusNotFound, gin.H{"error": "no such key"})
+	case errors.Is(err, apikeys.ErrConfigKey):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, apiKeys.Status(c.Param("id")))
+	}
+}
+
+// jobStatus is a job as the jobs API shows it
+type jobStatus struct {
+	jobs.Job
+	Pending int         `json:"pending"`
+	Results string      `json:"results,omitempty"` // download URL once finished
+	Items   []jobs.Item `json:"items,omitempty"`   // per-package progress
+}
+
+func newJobStatus(job jobs.Job) jobStatus {
+	st := jobStatus{Job: job, Pending: job.Pending()}
+	if job.Finished() {
+		st.Results = "/api/jobs/" + job.ID + "/results"
+	}
+	return st
+}
+
+// jobOwner is the API key id recorded with submitted jobs ("" without auth)
+func jobOwner(c *gin.Context) string {
+	if key := apikeys.KeyFrom(c); key != nil {
+		return key.ID
+	}
+	return ""
+}
+
+// findJob loads the job named in the URL, answering 404 if it doesn't exist
+// or belongs to another (non-admin) API key
+func findJob(c *gin.Context) (jobs.Job, bool) {
+	job, err := jobManager.Store.Get(c.Param("id"))
+	if err == nil {
+		key := apikeys.KeyFrom(c)
+		if key == nil || key.Admin || job.Owner == key.ID {
+			return job, true
+		}
+		err = jobs.ErrNotFound
+	}
+
+	if errors.Is(err, jobs.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return job, false
+}
+
+// apiJobCreate handles POST /api/jobs {"packages": ["com.a", "com.b", ...]}
+// — up to MaxJobSize packages, fetched in the background. It answers 202
+// with the queued job; poll GET /api/jobs/:id for progress.
+func apiJobCreate(c *gin.Context) {
+	pkgs, err := requestPackages(c, MaxJobSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// a job bigger than the rest of the day's quota is refused up front
+	if !chargePackages(c, pkgs, nil) {
+		return
+	}
+
+	job, err := jobManager.Submit(pkgs, jobOwner(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Location", "/api/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, newJobStatus(job))
+}
+
+// apiJobs handles GET /api/jobs?status=queued|running|done|cancelled
+func apiJobs(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", jobs.StatusQueued, jobs.StatusRunning, jobs.StatusDone, jobs.StatusCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be queued, running, done or cancelled"})
+		return
+	}
+
+	all, err := jobManager.Store.List(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	key := apikeys.KeyFrom(c)
+	out := []jobStatus{}
+	for _, job := range all {
+		if key == nil || key.Admin || job.Owner == key.ID {
+			out = append(out, newJobStatus(job))
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": out})
+}
+
+// apiJob handles GET /api/jobs/:id (state, counters and per-package progress)
+func apiJob(c *gin.Context) {
+	job, ok := findJob(c)
+	if !ok {
+		return
+	}
+
+	items, err := jobManager.Store.Items(job.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	st := newJobStatus(job)
+	st.Items = items
+	c.JSON(http.StatusOK, st)
+}
+
+// apiJobResults handles GET /api/jobs/:id/results[?format=csv|xlsx] once the
+// job is done or cancelled (409 before that)
+func apiJobResults(c *gin.Context) {
+	format, ok := requestFormat(c)
+	if !ok {
+		return
+	}
+
+	job, ok := findJob(c)
+	if !ok {
+		return
+	}
+	if !job.Finished() {
+		c.JSON(http.StatusConflict, gin.H{"error": "job is " + job.Status + ", results are available once it is done", "job": newJobStatus(job)})
+		return
+	}
+
+	items, err := jobManager.Store.Items(job.ID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if output.IsExportFormat(format) {
+		rows := make([]output.ExportRow, 0, len(items))
+		for _, it := range items {
+			// items a cancelled job never reached have neither an app nor an error
+			if it.App != nil || it.Error != "" {
+				rows = append(rows, output.ExportRow{Package: it.Package, App: it.App, Error: it.Error})
+			}
+		}
+		output.SendExport(c, format, "job-"+job.ID, rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": newJobStatus(job), "results": items})
+}
+
+// apiJobCancel handles POST /api/jobs/:id/cancel; results fetched so far are kept
+func apiJobCancel(c *gin.Context) {
+	job, ok := findJob(c)
+	if !ok {
+		return
+	}
+
+	job, err := jobManager.Cancel(job.ID)
+	switch {
+	case errors.Is(err, jobs.ErrFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, jobs.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, newJobStatus(job))
+	}
+}
@@ -0,0 +1,520 @@
+// Package apiclient is a Go client for the server's JSON API (described in
+// openapi/openapi.yaml). It decodes into the same types the handlers
+// encode, and its contract tests run it against the real handlers, checking
+// every answer against the OpenAPI document.
+//
+//	c := apiclient.New("http://localhost:8000", os.Getenv("PLAYSTORE_API_KEY"))
+//	app, err := c.App(ctx, "com.whatsapp")
+//
+// The server-sent event streams (/api/batch/stream, /api/jobs/{id}/events)
+// and the CSV/XLSX downloads are not wrapped.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/alerts"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/apikeys"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/compare"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/history"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/jobs"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/watchlist"
+)
+
+// Client calls one server. The zero HTTPClient means http.DefaultClient.
+type Client struct {
+	BaseURL    string // e.g. http://localhost:8000
+	APIKey     string // sent as X-API-Key ("" = none, for API_AUTH=off)
+	HTTPClient *http.Client
+}
+
+// New returns a client for the server at baseURL
+func New(baseURL, apiKey string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), APIKey: apiKey}
+}
+
+// Error is a non-2xx answer
+type Error struct {
+	StatusCode int
+	Message    string        // the "error" of the body
+	RetryAfter time.Duration // from Retry-After on 429
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("playstore api: %d %s", e.StatusCode, e.Message)
+}
+
+// StatusCode returns err's HTTP status (0 if err isn't an *Error)
+func StatusCode(err error) int {
+	if e, ok := err.(*Error); ok {
+		return e.StatusCode
+	}
+	return 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// APPS
+///////////////////////////////////////////////////////////////////////////////
+
+// BatchResult is one package of a batch or comparison
+type BatchResult struct {
+	Package string      `json:"package"`
+	App     *parser.App `json:"app,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// App returns an app's details (GET /api/app-info)
+func (c *Client) App(ctx context.Context, pkg string) (*parser.App, error) {
+	var app parser.App
+	err := c.do(ctx, http.MethodGet, "/api/app-info", url.Values{"package": {pkg}}, nil, &app)
+	return &app, err
+}
+
+// AppImages returns an app's details with the icon at icon and the
+// screenshots and feature graphic at images (zero = as scraped; one 0 side
+// keeps the aspect ratio)
+func (c *Client) AppImages(ctx context.Context, pkg string, icon, images parser.ImageSize) (*parser.App, error) {
+	q := url.Values{"package": {pkg}}
+	for name, size := range map[string]parser.ImageSize{"iconSize": icon, "imageSize": images} {
+		if !size.IsZero() {
+			q.Set(name, fmt.Sprintf("%dx%d", size.Width, size.Height))
+		}
+	}
+	var app parser.App
+	err := c.do(ctx, http.MethodGet, "/api/app-info", q, nil, &app)
+	return &app, err
+}
+
+// AppDiagnostics returns an app's details with the parser's per-field
+// diagnostics (GET /api/app-info?debug=1)
+func (c *Client) AppDiagnostics(ctx context.Context, pkg string) (*parser.App, *parser.Diagnostics, error) {
+	var body struct {
+		parser.App
+		Diagnostics *parser.Diagnostics `json:"diagnostics"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/app-info", url.Values{"package": {pkg}, "debug": {"1"}}, nil, &body)
+	return &body.App, body.Diagnostics, err
+}
+
+// Reviews returns up to limit reviews of pkg (0 = the server's default)
+func (c *Client) Reviews(ctx context.Context, pkg string, limit int) ([]parser.Review, error) {
+	var body struct {
+		Reviews []parser.Review `json:"reviews"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/reviews/"+url.PathEscape(pkg), limitQuery(limit), nil, &body)
+	return body.Reviews, err
+}
+
+// Search returns the apps Google Play lists for query
+func (c *Client) Search(ctx context.Context, query string) ([]parser.SearchResult, error) {
+	var body struct {
+		Results []parser.SearchResult `json:"results"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/search", url.Values{"q": {query}}, nil, &body)
+	return body.Results, err
+}
+
+// Batch fetches up to 50 packages, one result per distinct package in
+// request order (larger batches: SubmitJob)
+func (c *Client) Batch(ctx context.Context, pkgs []string) ([]BatchResult, error) {
+	var body struct {
+		Results []BatchResult `json:"results"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/batch", nil, packagesBody(pkgs), &body)
+	return body.Results, err
+}
+
+// Compare lines up 2 to 10 apps
+func (c *Client) Compare(ctx context.Context, pkgs []string) (*compare.Table, error) {
+	var table compare.Table
+	err := c.do(ctx, http.MethodGet, "/api/compare", url.Values{"packages": {strings.Join(pkgs, ",")}}, nil, &table)
+	return &table, err
+}
+
+// GraphQL runs a query, decoding "data" into out. Query errors come back as
+// a *GraphQLError along with whatever data was resolved.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	var body struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	req := map[string]interface{}{"query": query, "variables": variables}
+	if err := c.do(ctx, http.MethodPost, "/api/graphql", nil, req, &body); err != nil {
+		return err
+	}
+	if out != nil && len(body.Data) > 0 {
+		if err := json.Unmarshal(body.Data, out); err != nil {
+			return err
+		}
+	}
+	if len(body.Errors) > 0 {
+		gerr := &GraphQLError{}
+		for _, e := range body.Errors {
+			gerr.Messages = append(gerr.Messages, e.Message)
+		}
+		return gerr
+	}
+	return nil
+}
+
+// GraphQLError lists the errors of a GraphQL answer
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "graphql: " + strings.Join(e.Messages, "; ")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// HISTORY
+///////////////////////////////////////////////////////////////////////////////
+
+// Range limits history answers; zero times are the server's defaults (the
+// last 30 days)
+type Range struct {
+	From, To time.Time
+}
+
+func (r Range) query() url.Values {
+	q := url.Values{}
+	if !r.From.IsZero() {
+		q.Set("from", r.From.Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		q.Set("to", r.To.Format(time.RFC3339))
+	}
+	return q
+}
+
+// Series is a package's history
+type Series struct {
+	Package  string           `json:"package"`
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Interval history.Interval `json:"interval"`
+	Points   []history.Point  `json:"points"`
+}
+
+// History returns pkg's rating and installs over r ("" interval = raw)
+func (c *Client) History(ctx context.Context, pkg string, r Range, interval history.Interval) (*Series, error) {
+	q := r.query()
+	if interval != "" {
+		q.Set("interval", string(interval))
+	}
+	var s Series
+	err := c.do(ctx, http.MethodGet, "/api/history/"+url.PathEscape(pkg), q, nil, &s)
+	return &s, err
+}
+
+// Changes returns the snapshots of pkg within r that changed something
+func (c *Client) Changes(ctx context.Context, pkg string, r Range) ([]history.Snapshot, error) {
+	var body struct {
+		Snapshots []history.Snapshot `json:"snapshots"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/history/"+url.PathEscape(pkg)+"/changes", r.query(), nil, &body)
+	return body.Snapshots, err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// WATCHLIST AND ALERTS
+///////////////////////////////////////////////////////////////////////////////
+
+// Watchlist returns the watched packages
+func (c *Client) Watchlist(ctx context.Context) ([]watchlist.Entry, error) {
+	var body struct {
+		Packages []watchlist.Entry `json:"packages"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/watchlist", nil, nil, &body)
+	return body.Packages, err
+}
+
+// Watch adds pkg to the watchlist
+func (c *Client) Watch(ctx context.Context, pkg string) (*watchlist.Entry, error) {
+	var e watchlist.Entry
+	err := c.do(ctx, http.MethodPost, "/api/watchlist", nil, map[string]string{"package": pkg}, &e)
+	return &e, err
+}
+
+// Unwatch removes pkg from the watchlist
+func (c *Client) Unwatch(ctx context.Context, pkg string) error {
+	return c.do(ctx, http.MethodDelete, "/api/watchlist/"+url.PathEscape(pkg), nil, nil, nil)
+}
+
+// AlertRules returns every alert rule
+func (c *Client) AlertRules(ctx context.Context) ([]alerts.Rule, error) {
+	var body struct {
+		Rules []alerts.Rule `json:"rules"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/alerts/rules", nil, nil, &body)
+	return body.Rules, err
+}
+
+// AddAlertRule saves rule, returning it with its id
+func (c *Client) AddAlertRule(ctx context.Context, rule alerts.Rule) (*alerts.Rule, error) {
+	var saved alerts.Rule
+	err := c.do(ctx, http.MethodPost, "/api/alerts/rules", nil, rule, &saved)
+	return &saved, err
+}
+
+// DeleteAlertRule deletes rule id
+func (c *Client) DeleteAlertRule(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/alerts/rules/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// TestAlertRule sends rule id's webhook now and returns the delivery
+func (c *Client) TestAlertRule(ctx context.Context, id string) (*alerts.Delivery, error) {
+	var d alerts.Delivery
+	err := c.do(ctx, http.MethodPost, "/api/alerts/rules/"+url.PathEscape(id)+"/test", nil, nil, &d)
+	return &d, err
+}
+
+// AlertDeliveries returns the newest webhook deliveries, of rule id only
+// unless it is "" (limit 0 = the server's default)
+func (c *Client) AlertDeliveries(ctx context.Context, ruleID string, limit int) ([]alerts.Delivery, error) {
+	q := limitQuery(limit)
+	if ruleID != "" {
+		q.Set("rule", ruleID)
+	}
+	var body struct {
+		Deliveries []alerts.Delivery `json:"deliveries"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/alerts/deliveries", q, nil, &body)
+	return body.Deliveries, err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PARSER RULES
+///////////////////////////////////////////////////////////////////////////////
+
+// ParserRules returns the selector rules in use and the file they came
+// from ("" = bundled)
+func (c *Client) ParserRules(ctx context.Context) (*parser.Rules, string, error) {
+	var body struct {
+		Path  string        `json:"path"`
+		Rules *parser.Rules `json:"rules"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/parser/rules", nil, nil, &body)
+	return body.Rules, body.Path, err
+}
+
+// ReloadParserRules reloads the server's rules file (admin key), returning
+// the version loaded
+func (c *Client) ReloadParserRules(ctx context.Context) (int, error) {
+	var body struct {
+		Version int `json:"version"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/parser/rules/reload", nil, nil, &body)
+	return body.Version, err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// BATCH JOBS
+///////////////////////////////////////////////////////////////////////////////
+
+// Job is a batch job as the jobs API shows it
+type Job struct {
+	jobs.Job
+	Pending int         `json:"pending"`
+	Results string      `json:"results,omitempty"` // download URL once finished
+	Items   []jobs.Item `json:"items,omitempty"`   // only from Job
+}
+
+// SubmitJob queues up to 10000 packages
+func (c *Client) SubmitJob(ctx context.Context, pkgs []string) (*Job, error) {
+	var job Job
+	err := c.do(ctx, http.MethodPost, "/api/jobs", nil, packagesBody(pkgs), &job)
+	return &job, err
+}
+
+// Jobs lists the caller's jobs, newest first ("" status = all)
+func (c *Client) Jobs(ctx context.Context, status string) ([]Job, error) {
+	q := url.Values{}
+	if status != "" {
+		q.Set("status", status)
+	}
+	var body struct {
+		Jobs []Job `json:"jobs"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/jobs", q, nil, &body)
+	return body.Jobs, err
+}
+
+// Job returns a job with its per-package progress
+func (c *Client) Job(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := c.do(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(id), nil, nil, &job)
+	return &job, err
+}
+
+// JobResults returns every package of a finished job with its app or error
+// (a 409 *Error while it is queued or running)
+func (c *Client) JobResults(ctx context.Context, id string) ([]jobs.Item, error) {
+	var body struct {
+		Results []jobs.Item `json:"results"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(id)+"/results", nil, nil, &body)
+	return body.Results, err
+}
+
+// CancelJob cancels a queued or running job
+func (c *Client) CancelJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := c.do(ctx, http.MethodPost, "/api/jobs/"+url.PathEscape(id)+"/cancel", nil, nil, &job)
+	return &job, err
+}
+
+// WaitJob polls job id every interval until it has finished or ctx is done
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (*Job, error) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil || job.Finished() {
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// ADMIN (admin key)
+///////////////////////////////////////////////////////////////////////////////
+
+// NewKey is a key to create; zero limits are the server's defaults
+type NewKey struct {
+	Name       string  `json:"name"`
+	Admin      bool    `json:"admin"`
+	RateLimit  float64 `json:"rateLimit,omitempty"`
+	Burst      int     `json:"burst,omitempty"`
+	DailyQuota int     `json:"dailyQuota,omitempty"`
+}
+
+// Keys lists every API key with today's usage
+func (c *Client) Keys(ctx context.Context) ([]apikeys.Status, error) {
+	var body struct {
+		Keys []apikeys.Status `json:"keys"`
+	}
+	err := c.do(ctx, http.MethodGet, "/admin/keys", nil, nil, &body)
+	return body.Keys, err
+}
+
+// CreateKey creates a key and returns it with its secret (shown only once)
+func (c *Client) CreateKey(ctx context.Context, k NewKey) (*apikeys.Status, string, error) {
+	var body struct {
+		Key    apikeys.Status `json:"key"`
+		Secret string         `json:"secret"`
+	}
+	err := c.do(ctx, http.MethodPost, "/admin/keys", nil, k, &body)
+	return &body.Key, body.Secret, err
+}
+
+// Key returns a key with its usage per day
+func (c *Client) Key(ctx context.Context, id string) (*apikeys.Status, error) {
+	var st apikeys.Status
+	err := c.do(ctx, http.MethodGet, "/admin/keys/"+url.PathEscape(id), nil, nil, &st)
+	return &st, err
+}
+
+// RevokeKey revokes a key created through the API
+func (c *Client) RevokeKey(ctx context.Context, id string) (*apikeys.Status, error) {
+	var st apikeys.Status
+	err := c.do(ctx, http.MethodDelete, "/admin/keys/"+url.PathEscape(id), nil, nil, &st)
+	return &st, err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// TRANSPORT
+///////////////////////////////////////////////////////////////////////////////
+
+func packagesBody(pkgs []string) interface{} {
+	return map[string][]string{"packages": pkgs}
+}
+
+func limitQuery(limit int) url.Values {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	return q
+}
+
+// do sends a request with an optional JSON body and decodes a 2xx JSON
+// answer into out (nil = discard); anything else is an *Error
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.APIKey != "" {
+		req.Header.Set(apikeys.Header, c.APIKey)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		apiErr := &Error{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			apiErr.Message = e.Error
+		}
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
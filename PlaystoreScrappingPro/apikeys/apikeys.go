@@ -0,0 +1,443 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Layout:
+//
+//	"api-keys"      key id -> JSON Key (only keys created through the admin API)
+//	"api-key-usage" key id -> JSON usage (requests per UTC day, last use)
+const (
+	keysBucket  = "api-keys"
+	usageBucket = "api-key-usage"
+)
+
+// Key sources
+const (
+	SourceConfig = "config" // API_KEYS / API_ADMIN_KEY, can't be revoked at runtime
+	SourceStore  = "store"  // created with POST /admin/keys
+)
+
+// ErrConfigKey is returned when revoking a key that comes from the configuration
+var ErrConfigKey = errors.New("key comes from the configuration; remove it there")
+
+// usageDays is how many days of per-day counts are kept
+const usageDays = 90
+
+// Key is one API client. The secret itself is never stored, only its SHA-256.
+type Key struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // start of the secret, to recognise it
+	Hash       string     `json:"hash,omitempty"`
+	Admin      bool       `json:"admin"`
+	RateLimit  float64    `json:"rateLimit"`  // requests per second, 0 = unlimited
+	Burst      int        `json:"burst"`      // requests allowed at once
+	DailyQuota int        `json:"dailyQuota"` // requests per UTC day, 0 = unlimited
+	Source     string     `json:"source"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Spec is what a key is created from. Limits left nil take the store's
+// defaults (admin keys default to no daily quota); an explicit 0 rate limit
+// or daily quota means unlimited.
+type Spec struct {
+	Name       string   `json:"name"`
+	Admin      bool     `json:"admin"`
+	RateLimit  *float64 `json:"rateLimit"`
+	Burst      *int     `json:"burst"`
+	DailyQuota *int     `json:"dailyQuota"`
+}
+
+// Limits are applied to keys that don't set their own
+type Limits struct {
+	RateLimit  float64
+	Burst      int
+	DailyQuota int
+}
+
+// DefaultLimits are used unless API_RATE_LIMIT / API_DAILY_QUOTA say otherwise
+var DefaultLimits = Limits{RateLimit: 5, Burst: 10, DailyQuota: 5000}
+
+// Usage is a key's request count per UTC day (YYYY-MM-DD)
+type Usage struct {
+	LastUsed time.Time      `json:"lastUsed,omitempty"`
+	Days     map[string]int `json:"days"`
+
+	dirty bool
+}
+
+// Status is a key (without its hash) plus its usage, as shown by the admin API
+type Status struct {
+	Key
+	Today     int            `json:"today"`
+	Remaining *int           `json:"remaining,omitempty"` // nil when unlimited
+	LastUsed  time.Time      `json:"lastUsed,omitempty"`
+	Days      map[string]int `json:"days,omitempty"`
+}
+
+// Store holds every key, indexed by the hash of its secret
+type Store struct {
+	db     *storage.DB
+	limits Limits
+
+	mu     sync.RWMutex
+	keys   map[string]*Key // id -> key
+	byHash map[string]*Key
+	usage  map[string]*Usage
+}
+
+// NewStore loads the persisted keys and usage from db
+func NewStore(db *storage.DB, limits Limits) (*Store, error) {
+	if err := db.EnsureBuckets(keysBucket, usageBucket); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		db:     db,
+		limits: limits,
+		keys:   make(map[string]*Key),
+		byHash: make(map[string]*Key),
+		usage:  make(map[string]*Usage),
+	}
+
+	err := db.View(func(tx *bolt.Tx) error {
+		err := tx.Bucket([]byte(keysBucket)).ForEach(func(k, v []byte) error {
+			var key Key
+			if err := json.Unmarshal(v, &key); err != nil {
+				return fmt.Errorf("corrupt api key %s: %v", k, err)
+			}
+			s.add(&key)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket([]byte(usageBucket)).ForEach(func(k, v []byte) error {
+			u := &Usage{}
+			if err := json.Unmarshal(v, u); err != nil {
+				return fmt.Errorf("corrupt api key usage %s: %v", k, err)
+			}
+			if u.Days == nil {
+				u.Days = make(map[string]int)
+			}
+			s.usage[string(k)] = u
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AddConfigKey registers a key from configuration (not persisted)
+func (s *Store) AddConfigKey(name, secret string, admin bool) error {
+	if len(secret) < 16 {
+		return fmt.Errorf("api key %q is too short (16 characters minimum)", name)
+	}
+
+	key := &Key{
+		ID:        "cfg-" + name,
+		Name:      name,
+		Prefix:    prefix(secret),
+		Hash:      hash(secret),
+		Admin:     admin,
+		Source:    SourceConfig,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.applyLimits(key, Spec{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[key.ID]; ok {
+		return fmt.Errorf("api key %q configured twice", name)
+	}
+	s.add(key)
+	return nil
+}
+
+// ParseConfig parses API_KEYS: comma-separated name:secret pairs
+func ParseConfig(v string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, secret, ok := strings.Cut(item, ":")
+		if !ok || name == "" || secret == "" {
+			return nil, fmt.Errorf("expected name:secret, got %q", item)
+		}
+		out[name] = secret
+	}
+	return out, nil
+}
+
+// Create makes a new key and returns it with its secret, which is shown only
+// this once
+func (s *Store) Create(spec Spec) (Key, string, error) {
+	k := Key{Name: strings.TrimSpace(spec.Name), Admin: spec.Admin}
+	if k.Name == "" {
+		return k, "", fmt.Errorf("name is required")
+	}
+	if negative(spec.RateLimit) || negative(spec.Burst) || negative(spec.DailyQuota) {
+		return k, "", fmt.Errorf("rateLimit, burst and dailyQuota can't be negative")
+	}
+
+	secret := "psk_" + randomHex(24)
+	k.ID = randomHex(8)
+	k.Prefix = prefix(secret)
+	k.Hash = hash(secret)
+	k.Source = SourceStore
+	k.CreatedAt = time.Now().UTC()
+	s.applyLimits(&k, spec)
+
+	if err := s.save(&k); err != nil {
+		return k, "", err
+	}
+
+	s.mu.Lock()
+	s.add(&k)
+	s.mu.Unlock()
+
+	return k, secret, nil
+}
+
+// Revoke disables a key for good, reporting whether it existed
+func (s *Store) Revoke(id string) (bool, error) {
+	s.mu.Lock()
+	key, ok := s.keys[id]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if key.Source == SourceConfig {
+		return true, ErrConfigKey
+	}
+	if key.RevokedAt != nil {
+		return true, nil
+	}
+
+	revoked := *key
+	now := time.Now().UTC()
+	revoked.RevokedAt = &now
+	if err := s.save(&revoked); err != nil {
+		return true, err
+	}
+
+	s.mu.Lock()
+	key.RevokedAt = &now
+	s.mu.Unlock()
+	return true, nil
+}
+
+// Lookup returns the key with this secret (nil if unknown)
+func (s *Store) Lookup(secret string) *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if k, ok := s.byHash[hash(secret)]; ok {
+		c := *k
+		return &c
+	}
+	return nil
+}
+
+// Status returns one key and its usage (nil if it doesn't exist)
+func (s *Store) Status(id string) *Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	k, ok := s.keys[id]
+	if !ok {
+		return nil
+	}
+	st := s.status(k)
+	st.Days = make(map[string]int)
+	if u := s.usage[id]; u != nil {
+		for d, n := range u.Days {
+			st.Days[d] = n
+		}
+	}
+	return &st
+}
+
+// List returns every key with today's usage, oldest first
+func (s *Store) List() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Status, 0, len(s.keys))
+	for _, k := range s.keys {
+		out = append(out, s.status(k))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// Take records n requests by key id unless that would go over quota (0 =
+// unlimited), and returns the day's total. Checking and counting happen under
+// one lock, so concurrent requests can't overshoot the quota; when n don't
+// fit, none are taken.
+func (s *Store) Take(id string, at time.Time, quota, n int) (used int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.usage[id]
+	if u == nil {
+		u = &Usage{Days: make(map[string]int)}
+		s.usage[id] = u
+	}
+	d := day(at)
+	if quota > 0 && u.Days[d]+n > quota {
+		return u.Days[d], false
+	}
+	u.Days[d] += n
+	u.LastUsed = at.UTC()
+	u.dirty = true
+	return u.Days[d], true
+}
+
+// Used returns how many requests key id made on at's UTC day
+func (s *Store) Used(id string, at time.Time) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if u := s.usage[id]; u != nil {
+		return u.Days[day(at)]
+	}
+	return 0
+}
+
+// Flush persists usage counters changed since the last flush, dropping days
+// older than usageDays
+func (s *Store) Flush() error {
+	cutoff := day(time.Now().AddDate(0, 0, -usageDays))
+
+	s.mu.Lock()
+	pending := make(map[string][]byte)
+	for id, u := range s.usage {
+		if !u.dirty {
+			continue
+		}
+		for d := range u.Days {
+			if d < cutoff {
+				delete(u.Days, d)
+			}
+		}
+		data, err := json.Marshal(u)
+		if err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		pending[id] = data
+		u.dirty = false
+	}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(usageBucket))
+		for id, data := range pending {
+			if err := b.Put([]byte(id), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) status(k *Key) Status {
+	st := Status{Key: *k}
+	st.Hash = ""
+	if u := s.usage[k.ID]; u != nil {
+		st.Today = u.Days[day(time.Now())]
+		st.LastUsed = u.LastUsed
+	}
+	if k.DailyQuota > 0 {
+		left := max(k.DailyQuota-st.Today, 0)
+		st.Remaining = &left
+	}
+	return st
+}
+
+func (s *Store) add(k *Key) {
+	s.keys[k.ID] = k
+	s.byHash[k.Hash] = k
+}
+
+func (s *Store) save(k *Key) error {
+	data, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(keysBucket)).Put([]byte(k.ID), data)
+	})
+}
+
+// applyLimits sets k's limits from spec, using the store's defaults for the
+// ones spec leaves unset
+func (s *Store) applyLimits(k *Key, spec Spec) {
+	k.RateLimit, k.Burst = s.limits.RateLimit, s.limits.Burst
+	if !k.Admin {
+		k.DailyQuota = s.limits.DailyQuota
+	}
+	if spec.RateLimit != nil {
+		k.RateLimit = *spec.RateLimit
+	}
+	if spec.Burst != nil {
+		k.Burst = *spec.Burst
+	}
+	if spec.DailyQuota != nil {
+		k.DailyQuota = *spec.DailyQuota
+	}
+}
+
+func negative[T int | float64](v *T) bool {
+	return v != nil && *v < 0
+}
+
+func day(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func prefix(secret string) string {
+	if len(secret) > 8 {
+		return secret[:8]
+	}
+	return secret
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
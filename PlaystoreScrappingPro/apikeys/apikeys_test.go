@@ -0,0 +1,182 @@
+package apikeys
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+)
+
+var testLimits = Limits{RateLimit: 5, Burst: 10, DailyQuota: 100}
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := NewStore(db, testLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newStore(t *testing.T) *Store {
+	return openStore(t, filepath.Join(t.TempDir(), "test.db"))
+}
+
+func TestCreateLimits(t *testing.T) {
+	s := newStore(t)
+	zero, rate, burst := 0, 0.5, 2
+
+	for _, tt := range []struct {
+		name      string
+		spec      Spec
+		rateLimit float64
+		burst     int
+		quota     int
+	}{
+		{"defaults", Spec{Name: "ci"}, 5, 10, 100},
+		{"admin has no quota", Spec{Name: "ops", Admin: true}, 5, 10, 0},
+		{"explicit 0 quota is unlimited", Spec{Name: "bulk", DailyQuota: &zero}, 5, 10, 0},
+		{"own rate limit", Spec{Name: "slow", RateLimit: &rate, Burst: &burst}, 0.5, 2, 100},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			k, secret, err := s.Create(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.RateLimit != tt.rateLimit || k.Burst != tt.burst || k.DailyQuota != tt.quota {
+				t.Errorf("limits = %v/%d/%d, want %v/%d/%d", k.RateLimit, k.Burst, k.DailyQuota, tt.rateLimit, tt.burst, tt.quota)
+			}
+			if got := s.Lookup(secret); got == nil || got.ID != k.ID {
+				t.Errorf("Lookup(secret) = %v, want key %s", got, k.ID)
+			}
+		})
+	}
+
+	negative := -1
+	if _, _, err := s.Create(Spec{Name: "bad", DailyQuota: &negative}); err == nil {
+		t.Error("Create accepts a negative quota")
+	}
+	if _, _, err := s.Create(Spec{Name: "  "}); err == nil {
+		t.Error("Create accepts an empty name")
+	}
+}
+
+func TestTakeQuota(t *testing.T) {
+	s := newStore(t)
+	today := time.Date(2026, time.March, 1, 23, 59, 0, 0, time.UTC)
+
+	for i := 1; i <= 3; i++ {
+		if used, ok := s.Take("k", today, 3, 1); !ok || used != i {
+			t.Fatalf("take %d: used=%d ok=%v", i, used, ok)
+		}
+	}
+	if used, ok := s.Take("k", today, 3, 1); ok || used != 3 {
+		t.Errorf("over quota: used=%d ok=%v, want 3 false", used, ok)
+	}
+
+	// a new UTC day starts from zero
+	if used, ok := s.Take("k", today.Add(2*time.Minute), 3, 1); !ok || used != 1 {
+		t.Errorf("next day: used=%d ok=%v, want 1 true", used, ok)
+	}
+
+	// n that don't fit are not taken at all
+	if used, ok := s.Take("k", today.Add(2*time.Minute), 3, 3); ok || used != 1 {
+		t.Errorf("take 3 of 2 left: used=%d ok=%v, want 1 false", used, ok)
+	}
+	if used, ok := s.Take("k", today.Add(2*time.Minute), 3, 2); !ok || used != 3 {
+		t.Errorf("take 2 of 2 left: used=%d ok=%v, want 3 true", used, ok)
+	}
+
+	// 0 is unlimited
+	if _, ok := s.Take("u", today, 0, 1000); !ok {
+		t.Error("unlimited quota refused")
+	}
+}
+
+func TestTakeConcurrent(t *testing.T) {
+	s := newStore(t)
+	now := time.Now()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		granted int
+	)
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := s.Take("k", now, 20, 1); ok {
+				mu.Lock()
+				granted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if granted != 20 || s.Used("k", now) != 20 {
+		t.Errorf("granted %d, used %d, want 20 each", granted, s.Used("k", now))
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s := openStore(t, path)
+
+	k, secret, err := s.Create(Spec{Name: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Revoke(k.ID); !ok || err != nil {
+		t.Fatalf("Revoke = %v, %v", ok, err)
+	}
+	if got := s.Lookup(secret); got == nil || got.RevokedAt == nil {
+		t.Errorf("Lookup after revoke = %+v, want a revoked key", got)
+	}
+	if ok, _ := s.Revoke("missing"); ok {
+		t.Error("Revoke reports an unknown key as existing")
+	}
+
+	if err := s.AddConfigKey("ops", "ops-secret-0123456789", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Revoke("cfg-ops"); err != ErrConfigKey {
+		t.Errorf("revoke config key: %v, want ErrConfigKey", err)
+	}
+
+	// the revocation is persisted
+	s.db.Close()
+	s = openStore(t, path)
+	if got := s.Lookup(secret); got == nil || got.RevokedAt == nil {
+		t.Errorf("Lookup after reopening = %+v, want a revoked key", got)
+	}
+}
+
+func TestFlushUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s := openStore(t, path)
+	now := time.Now()
+
+	s.Take("k", now, 0, 7)
+	s.Take("k", now.AddDate(0, 0, -usageDays-1), 0, 1) // too old to keep
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.db.Close()
+	s = openStore(t, path)
+	if got := s.Used("k", now); got != 7 {
+		t.Errorf("usage after reopening = %d, want 7", got)
+	}
+	if got := s.Used("k", now.AddDate(0, 0, -usageDays-1)); got != 0 {
+		t.Errorf("usage older than %d days = %d, want it dropped", usageDays, got)
+	}
+}
@@ -0,0 +1,356 @@
+package apikeys
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// Header carries the API key ("Authorization: Bearer <key>" works too)
+const Header = "X-API-Key"
+
+// FormField carries the API key of an HTML form (see RequireKeyForm)
+const FormField = "api_key"
+
+// contextKey is where the middleware stores the caller's *Key, in the gin
+// context and in the request's context.Context
+const contextKey = "apikeys.key"
+
+type ctxKey struct{}
+
+// Guard enforces API keys, per-key rate limits and daily quotas on inbound
+// routes. Rejections are 401 (no/bad key), 403 (not an admin) or 429 with
+// Retry-After.
+type Guard struct {
+	store *Store
+	anon  Limits // per client IP, for routes open to anonymous callers
+
+	mu       sync.Mutex
+	limiters map[string]*limiterEntry // "key:<id>" or "ip:<addr>"
+}
+
+type limiterEntry struct {
+	lim      *rate.Limiter
+	lastSeen time.Time
+}
+
+// RejectFunc writes a rejection (the default answers JSON {"error": ...})
+type RejectFunc func(c *gin.Context, status int, message string)
+
+// NewGuard returns a guard for store; anon limits anonymous callers per IP
+func NewGuard(store *Store, anon Limits) *Guard {
+	return &Guard{store: store, anon: anon, limiters: make(map[string]*limiterEntry)}
+}
+
+// RequireKey rejects requests without a valid, unrevoked key and applies the
+// key's rate limit and daily quota
+func (g *Guard) RequireKey() gin.HandlerFunc {
+	return g.requireKey(false, false, rejectJSON)
+}
+
+// RequireAdminKey is RequireKey for admin keys only (403 for other keys)
+func (g *Guard) RequireAdminKey() gin.HandlerFunc {
+	return g.requireKey(true, false, rejectJSON)
+}
+
+// RequireKeyForm is RequireKey for HTML forms, which may post the key in
+// FormField. reject renders rejections (nil = JSON).
+func (g *Guard) RequireKeyForm(reject RejectFunc) gin.HandlerFunc {
+	if reject == nil {
+		reject = rejectJSON
+	}
+	return g.requireKey(false, true, reject)
+}
+
+func (g *Guard) requireKey(admin, form bool, reject RejectFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := secretFrom(c)
+		if secret == "" && form {
+			secret = strings.TrimSpace(c.PostForm(FormField))
+		}
+		if secret == "" {
+			reject(c, http.StatusUnauthorized, "missing API key (send it in the "+Header+" header)")
+			c.Abort()
+			return
+		}
+		key := g.store.Lookup(secret)
+		if key == nil || key.RevokedAt != nil {
+			reject(c, http.StatusUnauthorized, "invalid or revoked API key")
+			c.Abort()
+			return
+		}
+		if admin && !key.Admin {
+			reject(c, http.StatusForbidden, "admin API key required")
+			c.Abort()
+			return
+		}
+		if g.admitKey(c, key, reject) {
+			setKey(c, key)
+			c.Next()
+		}
+	}
+}
+
+// AllowAnonymous accepts a key when one is sent (with its limits) and
+// otherwise rate limits the caller by IP. reject renders rejections (nil =
+// JSON).
+func (g *Guard) AllowAnonymous(reject RejectFunc) gin.HandlerFunc {
+	if reject == nil {
+		reject = rejectJSON
+	}
+	return func(c *gin.Context) {
+		if secret := secretFrom(c); secret != "" {
+			key := g.store.Lookup(secret)
+			if key == nil || key.RevokedAt != nil {
+				reject(c, http.StatusUnauthorized, "invalid or revoked API key")
+				c.Abort()
+				return
+			}
+			if g.admitKey(c, key, reject) {
+				setKey(c, key)
+				c.Next()
+			}
+			return
+		}
+
+		if wait, ok := g.allow("ip:"+c.ClientIP(), g.anon.RateLimit, g.anon.Burst); !ok {
+			setRetryAfter(c, wait)
+			reject(c, http.StatusTooManyRequests, "too many requests, slow down or use an API key")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin only lets admin keys through; it must follow RequireKey on
+// routes of a group that is otherwise open to every key
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := KeyFrom(c); key == nil || !key.Admin {
+			rejectJSON(c, http.StatusForbidden, "admin API key required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// KeyFrom returns the key that authenticated the request (nil if anonymous)
+func KeyFrom(c *gin.Context) *Key {
+	if v, ok := c.Get(contextKey); ok {
+		return v.(*Key)
+	}
+	return nil
+}
+
+// WithKey returns ctx carrying the key that authenticated a call
+func WithKey(ctx context.Context, key *Key) context.Context {
+	return context.WithValue(ctx, ctxKey{}, key)
+}
+
+// KeyFromContext returns the key WithKey (or the middleware) put in ctx
+func KeyFromContext(ctx context.Context) *Key {
+	key, _ := ctx.Value(ctxKey{}).(*Key)
+	return key
+}
+
+func setKey(c *gin.Context, key *Key) {
+	c.Set(contextKey, key)
+	c.Request = c.Request.WithContext(WithKey(c.Request.Context(), key))
+}
+
+// Lookup returns the key with this secret (nil if unknown)
+func (g *Guard) Lookup(secret string) *Key {
+	return g.store.Lookup(secret)
+}
+
+// Rejection says why Admit turned a request away
+type Rejection struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+// RetrySeconds is RetryAfter in whole seconds, as sent in Retry-After
+func (r *Rejection) RetrySeconds() int {
+	return retrySeconds(r.RetryAfter)
+}
+
+// Admit applies key's rate limit and takes one request from its daily quota.
+// It returns the day's usage and, when the request must be refused, why. The
+// HTTP middleware and the gRPC interceptors both go through it.
+func (g *Guard) Admit(key *Key) (int, *Rejection) {
+	now := time.Now()
+
+	if wait, ok := g.allow("key:"+key.ID, key.RateLimit, key.Burst); !ok {
+		return g.store.Used(key.ID, now), &Rejection{
+			Message:    "rate limit of " + strconv.FormatFloat(key.RateLimit, 'f', -1, 64) + " requests/second exceeded",
+			RetryAfter: wait,
+		}
+	}
+
+	used, ok := g.store.Take(key.ID, now, key.DailyQuota, 1)
+	if !ok {
+		return used, &Rejection{
+			Message:    "daily quota of " + strconv.Itoa(key.DailyQuota) + " requests exhausted",
+			RetryAfter: untilNextDay(now),
+		}
+	}
+	return used, nil
+}
+
+// Charge takes n more requests from the daily quota of the key in ctx, for
+// a call that fetches several packages: the quota counts pages scraped, not
+// calls. Nothing is taken when the rest of the day's quota can't cover all
+// n. Anonymous calls, and every call when g is nil, are not charged.
+func (g *Guard) Charge(ctx context.Context, n int) *Rejection {
+	_, rej := g.charge(KeyFromContext(ctx), n)
+	return rej
+}
+
+// ChargeRequest is Charge for a gin request: it updates
+// X-RateLimit-Remaining, or answers 429 through reject (nil = JSON) and
+// returns false
+func (g *Guard) ChargeRequest(c *gin.Context, n int, reject RejectFunc) bool {
+	key := KeyFrom(c)
+	used, rej := g.charge(key, n)
+	if rej != nil {
+		if reject == nil {
+			reject = rejectJSON
+		}
+		setRetryAfter(c, rej.RetryAfter)
+		reject(c, http.StatusTooManyRequests, rej.Message)
+		c.Abort()
+		return false
+	}
+	if key != nil && key.DailyQuota > 0 {
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(max(key.DailyQuota-used, 0)))
+	}
+	return true
+}
+
+func (g *Guard) charge(key *Key, n int) (int, *Rejection) {
+	if g == nil || key == nil || n <= 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	used, ok := g.store.Take(key.ID, now, key.DailyQuota, n)
+	if !ok {
+		return used, &Rejection{
+			Message: fmt.Sprintf("daily quota of %d requests can't cover %d more packages (%d left today)",
+				key.DailyQuota, n, max(key.DailyQuota-used, 0)),
+			RetryAfter: untilNextDay(now),
+		}
+	}
+	return used, nil
+}
+
+// admitKey runs Admit for a gin request, setting the quota headers and
+// rejecting the request if it was refused
+func (g *Guard) admitKey(c *gin.Context, key *Key, reject RejectFunc) bool {
+	used, rej := g.Admit(key)
+	if key.DailyQuota > 0 {
+		c.Header("X-RateLimit-Limit", strconv.Itoa(key.DailyQuota))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(max(key.DailyQuota-used, 0)))
+	}
+	if rej != nil {
+		setRetryAfter(c, rej.RetryAfter)
+		reject(c, http.StatusTooManyRequests, rej.Message)
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// allow takes a token from the named limiter, or says how long to wait
+func (g *Guard) allow(name string, perSecond float64, burst int) (time.Duration, bool) {
+	if perSecond <= 0 {
+		return 0, true
+	}
+
+	g.mu.Lock()
+	e, ok := g.limiters[name]
+	if !ok {
+		e = &limiterEntry{lim: rate.NewLimiter(rate.Limit(perSecond), max(burst, 1))}
+		g.limiters[name] = e
+	}
+	e.lastSeen = time.Now()
+	g.mu.Unlock()
+
+	r := e.lim.Reserve()
+	if d := r.Delay(); d > 0 {
+		r.Cancel()
+		return d, false
+	}
+	return 0, true
+}
+
+// Run flushes usage counters every interval and forgets idle limiters,
+// until ctx is cancelled (then it flushes one last time)
+func (g *Guard) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			g.flush()
+			return
+		case <-ticker.C:
+			g.flush()
+			g.prune(10 * time.Minute)
+		}
+	}
+}
+
+func (g *Guard) flush() {
+	if err := g.store.Flush(); err != nil {
+		slog.Error("saving api key usage failed", "error", err)
+	}
+}
+
+func (g *Guard) prune(idle time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for name, e := range g.limiters {
+		if time.Since(e.lastSeen) > idle {
+			delete(g.limiters, name)
+		}
+	}
+}
+
+func secretFrom(c *gin.Context) string {
+	if v := strings.TrimSpace(c.GetHeader(Header)); v != "" {
+		return v
+	}
+	if v, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+func rejectJSON(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"error": message})
+}
+
+// setRetryAfter sets Retry-After in whole seconds (at least 1)
+func setRetryAfter(c *gin.Context, d time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(retrySeconds(d)))
+}
+
+func retrySeconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
+
+func untilNextDay(now time.Time) time.Duration {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC).Sub(now)
+}
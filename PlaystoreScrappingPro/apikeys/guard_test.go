@@ -0,0 +1,138 @@
+package apikeys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// guarded returns a router with the guard on GET /, answering 200
+func guarded(g *Guard) *gin.Engine {
+	r := gin.New()
+	r.GET("/", g.RequireKey(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func call(r *gin.Engine, secret string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if secret != "" {
+		req.Header.Set(Header, secret)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireKey(t *testing.T) {
+	s := newStore(t)
+	g := NewGuard(s, Limits{})
+	r := guarded(g)
+
+	quota := 2
+	_, secret, err := s.Create(Spec{Name: "ci", DailyQuota: &quota})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, revokedSecret, err := s.Create(Spec{Name: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Revoke(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := call(r, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no key: status = %d, want 401", w.Code)
+	}
+	if w := call(r, revokedSecret); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status = %d, want 401", w.Code)
+	}
+
+	for i := 1; i <= 2; i++ {
+		w := call(r, secret)
+		if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Remaining") != strconv.Itoa(2-i) {
+			t.Fatalf("request %d: status = %d remaining=%q", i, w.Code, w.Header().Get("X-RateLimit-Remaining"))
+		}
+	}
+	w := call(r, secret)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("over quota: status = %d, want 429", w.Code)
+	}
+	// Retry-After runs until the next UTC midnight
+	if secs, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || secs < 1 || secs > 24*60*60 {
+		t.Errorf("over quota: Retry-After = %q, want 1..86400 seconds", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	s := newStore(t)
+	g := NewGuard(s, Limits{})
+	r := guarded(g)
+
+	rate, burst, quota := 0.01, 2, 0
+	k, secret, err := s.Create(Spec{Name: "slow", RateLimit: &rate, Burst: &burst, DailyQuota: &quota})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if w := call(r, secret); w.Code != http.StatusOK {
+			t.Fatalf("request %d within burst: status = %d", i, w.Code)
+		}
+	}
+	w := call(r, secret)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("over rate limit: status = %d, want 429", w.Code)
+	}
+	// one token every 100s
+	if secs, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || secs < 90 || secs > 100 {
+		t.Errorf("over rate limit: Retry-After = %q, want about 100", w.Header().Get("Retry-After"))
+	}
+	// a rate-limited request doesn't count against the quota
+	if got := s.Used(k.ID, time.Now()); got != 2 {
+		t.Errorf("usage = %d, want 2", got)
+	}
+}
+
+func TestCharge(t *testing.T) {
+	s := newStore(t)
+	g := NewGuard(s, Limits{})
+
+	quota := 5
+	k, _, err := s.Create(Spec{Name: "ci", DailyQuota: &quota})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithKey(context.Background(), &k)
+
+	if _, rej := g.Admit(&k); rej != nil {
+		t.Fatal(rej.Message)
+	}
+	if rej := g.Charge(ctx, 3); rej != nil {
+		t.Fatalf("charge 3 of 4 left: %s", rej.Message)
+	}
+	rej := g.Charge(ctx, 2)
+	if rej == nil || rej.RetryAfter <= 0 {
+		t.Fatalf("charge 2 of 1 left: %+v, want a rejection with RetryAfter", rej)
+	}
+	if got := s.Used(k.ID, time.Now()); got != 4 {
+		t.Errorf("usage after a refused charge = %d, want 4", got)
+	}
+
+	// anonymous calls and a nil guard are not charged
+	if rej := g.Charge(context.Background(), 100); rej != nil {
+		t.Errorf("anonymous charge: %s", rej.Message)
+	}
+	if rej := (*Guard)(nil).Charge(ctx, 100); rej != nil {
+		t.Errorf("nil guard charge: %s", rej.Message)
+	}
+}
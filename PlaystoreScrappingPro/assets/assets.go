@@ -0,0 +1,425 @@
+// Package assets mirrors the images of scraped apps (icon, feature graphic
+// and screenshots) into a local directory and serves them, so exported
+// reports and history keep working after Google's image URLs change.
+//
+// Files are content addressed: <dir>/<hash[:2]>/<hash>, hash = hex SHA-256
+// of the bytes, so an image reachable from several URLs is stored once.
+// Bucket "assets" maps each source URL to its hash (a URL is downloaded
+// once) and bucket "asset-files" maps a hash to its File. Collect deletes
+// the files no snapshot references any more.
+package assets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	urlBucket  = "assets"
+	fileBucket = "asset-files"
+)
+
+// Defaults of NewStore
+const (
+	DefaultMaxSize     = 8 << 20 // bytes per image
+	DefaultConcurrency = 4
+	DefaultTimeout     = 10 * time.Second
+)
+
+// DefaultHosts are the image hosts Mirror downloads from (a host matches
+// itself and its subdomains); any other URL is left as it is
+var DefaultHosts = []string{"googleusercontent.com", "ggpht.com"}
+
+// imageTypes are the content types a file may have (sniffed from its bytes,
+// never taken from the upstream header)
+var imageTypes = map[string]bool{"image/png": true, "image/jpeg": true, "image/gif": true, "image/webp": true}
+
+var hashRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// File is one stored image
+type File struct {
+	Hash        string    `json:"hash"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastUsed    time.Time `json:"lastUsed"` // last stored or reused by Mirror
+}
+
+// Store keeps the files under a directory and their index in db
+type Store struct {
+	dir string
+	db  *storage.DB
+
+	BaseURL     string   // prefix of rewritten URLs, e.g. https://scraper.example.com ("" = relative)
+	Hosts       []string // hosts images are downloaded from
+	Client      *http.Client
+	MaxSize     int64
+	Concurrency int // downloads per Mirror call
+}
+
+// NewStore prepares dir and the asset buckets in db
+func NewStore(db *storage.DB, dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create asset directory: %v", err)
+	}
+	if err := db.EnsureBuckets(urlBucket, fileBucket); err != nil {
+		return nil, err
+	}
+	return &Store{
+		dir:         dir,
+		db:          db,
+		Hosts:       DefaultHosts,
+		Client:      &http.Client{Timeout: DefaultTimeout},
+		MaxSize:     DefaultMaxSize,
+		Concurrency: DefaultConcurrency,
+	}, nil
+}
+
+// Path is where the files are served (see Serve)
+const Path = "/assets/"
+
+// URL returns the address this server serves hash at
+func (s *Store) URL(hash string) string {
+	return strings.TrimRight(s.BaseURL, "/") + Path + hash
+}
+
+// Hash returns the hash of a URL returned by URL, false for any other URL
+func (s *Store) Hash(u string) (string, bool) {
+	rest, ok := strings.CutPrefix(u, strings.TrimRight(s.BaseURL, "/")+Path)
+	if !ok || !hashRe.MatchString(rest) {
+		return "", false
+	}
+	return rest, true
+}
+
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// MIRROR — download and rewrite
+///////////////////////////////////////////////////////////////////////////////
+
+// Mirror returns a copy of app whose images point at this server. An image
+// that can't be mirrored (other host, download failure, not an image) keeps
+// its original URL; failures are logged, not returned.
+func (s *Store) Mirror(ctx context.Context, app *parser.App) *parser.App {
+	out := *app
+	out.Screenshots = slices.Clone(app.Screenshots)
+	out.TabletScreenshots = slices.Clone(app.TabletScreenshots)
+
+	fields := []*string{&out.Icon, &out.FeatureGraphic}
+	for i := range out.Screenshots {
+		fields = append(fields, &out.Screenshots[i])
+	}
+	for i := range out.TabletScreenshots {
+		fields = append(fields, &out.TabletScreenshots[i])
+	}
+
+	log := logging.From(ctx)
+	sem := make(chan struct{}, max(s.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, f := range fields {
+		if *f == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(f *string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			local, err := s.Fetch(ctx, *f)
+			if err != nil {
+				log.Warn("asset not mirrored", "url", *f, "error", err)
+				return
+			}
+			*f = local
+		}(f)
+	}
+	wg.Wait()
+	return &out
+}
+
+var errHost = errors.New("host not mirrored")
+
+// Fetch stores the image at src (once per URL) and returns its local URL
+func (s *Store) Fetch(ctx context.Context, src string) (string, error) {
+	if _, ok := s.Hash(src); ok {
+		return src, nil // already ours
+	}
+	if !s.allowed(src) {
+		return "", errHost
+	}
+
+	if hash, ok := s.known(src); ok {
+		return s.URL(hash), nil
+	}
+
+	data, err := s.download(ctx, src)
+	if err != nil {
+		return "", err
+	}
+	ctype := http.DetectContentType(data)
+	if !imageTypes[ctype] {
+		return "", fmt.Errorf("not an image (%s)", ctype)
+	}
+
+	sum := sha256.Sum256(data)
+	now := time.Now().UTC()
+	file := File{Hash: hex.EncodeToString(sum[:]), ContentType: ctype, Size: int64(len(data)), CreatedAt: now, LastUsed: now}
+	if err := s.save(src, file, data); err != nil {
+		return "", err
+	}
+	return s.URL(file.Hash), nil
+}
+
+// allowed reports whether src is an https URL on one of Hosts
+func (s *Store) allowed(src string) bool {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := u.Hostname()
+	for _, h := range s.Hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// known returns the hash src was stored under, if its file still exists,
+// and marks the file used so Collect leaves it alone for a while
+func (s *Store) known(src string) (string, bool) {
+	var hash string
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(urlBucket)).Get([]byte(src))
+		if v == nil {
+			return nil
+		}
+		files := tx.Bucket([]byte(fileBucket))
+		meta := files.Get(v)
+		if meta == nil {
+			return nil
+		}
+		var f File
+		if err := json.Unmarshal(meta, &f); err != nil {
+			return err
+		}
+		if _, err := os.Stat(s.path(f.Hash)); err != nil {
+			return nil
+		}
+		f.LastUsed = time.Now().UTC()
+		data, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		hash = f.Hash
+		return files.Put(v, data)
+	})
+	return hash, err == nil && hash != ""
+}
+
+func (s *Store) download(ctx context.Context, src string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, s.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.MaxSize {
+		return nil, fmt.Errorf("larger than %d bytes", s.MaxSize)
+	}
+	return data, nil
+}
+
+// save writes the file (unless an identical one exists) and indexes it
+func (s *Store) save(src string, file File, data []byte) error {
+	// the same hash is the same bytes: an existing file is kept
+	path := s.path(file.Hash)
+	if _, err := os.Stat(path); err != nil {
+		if err := writeFile(path, data); err != nil {
+			return err
+		}
+	}
+
+	meta, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		files := tx.Bucket([]byte(fileBucket))
+		if v := files.Get([]byte(file.Hash)); v != nil {
+			// same image from another URL: keep its creation time
+			var old File
+			if json.Unmarshal(v, &old) == nil {
+				file.CreatedAt = old.CreatedAt
+				if meta, err = json.Marshal(file); err != nil {
+					return err
+				}
+			}
+		}
+		if err := files.Put([]byte(file.Hash), meta); err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(urlBucket)).Put([]byte(src), []byte(file.Hash))
+	})
+}
+
+// writeFile writes data to path through a temporary file, so a file is
+// either complete or absent
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// LOOKUP AND GARBAGE COLLECTION
+///////////////////////////////////////////////////////////////////////////////
+
+// File returns the stored file with hash, false if there is none
+func (s *Store) File(hash string) (File, bool, error) {
+	var file File
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(fileBucket)).Get([]byte(hash))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &file)
+	})
+	return file, found, err
+}
+
+// Open returns the content of a stored file
+func (s *Store) Open(hash string) (*os.File, error) {
+	if !hashRe.MatchString(hash) {
+		return nil, os.ErrNotExist
+	}
+	return os.Open(s.path(hash))
+}
+
+// Collected is the outcome of Collect
+type Collected struct {
+	Kept    int   `json:"kept"`
+	Removed int   `json:"removed"`
+	Freed   int64 `json:"freed"` // bytes
+}
+
+// Collect deletes every file whose local URL isn't in referenced, unless it
+// was used within grace (a snapshot may be about to reference it), along
+// with the source URLs pointing at it
+func (s *Store) Collect(referenced map[string]bool, grace time.Duration) (Collected, error) {
+	var res Collected
+	keep := make(map[string]bool)
+	for u := range referenced {
+		if hash, ok := s.Hash(u); ok {
+			keep[hash] = true
+		}
+	}
+	cutoff := time.Now().Add(-grace)
+
+	var doomed []File
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		files := tx.Bucket([]byte(fileBucket))
+		err := files.ForEach(func(k, v []byte) error {
+			var f File
+			if err := json.Unmarshal(v, &f); err != nil {
+				return fmt.Errorf("corrupt asset %s: %v", k, err)
+			}
+			if keep[f.Hash] || f.LastUsed.After(cutoff) {
+				res.Kept++
+				return nil
+			}
+			doomed = append(doomed, f)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		gone := make(map[string]bool, len(doomed))
+		for _, f := range doomed {
+			gone[f.Hash] = true
+			if err := files.Delete([]byte(f.Hash)); err != nil {
+				return err
+			}
+		}
+
+		// bolt forbids deleting while iterating: collect the keys first
+		urls := tx.Bucket([]byte(urlBucket))
+		var stale [][]byte
+		urls.ForEach(func(k, v []byte) error {
+			if gone[string(v)] {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		for _, k := range stale {
+			if err := urls.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+
+	for _, f := range doomed {
+		if err := os.Remove(s.path(f.Hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return res, err
+		}
+		res.Removed++
+		res.Freed += f.Size
+	}
+	return res, nil
+}
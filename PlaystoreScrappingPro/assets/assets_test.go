@@ -0,0 +1,196 @@
+package assets
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	iconPNG = []byte("\x89PNG\r\n\x1a\nicon")
+	shotPNG = []byte("\x89PNG\r\n\x1a\nscreenshot")
+)
+
+// stubImages serves fixed bodies by URL and counts the requests
+type stubImages struct {
+	bodies   map[string][]byte
+	requests atomic.Int32
+}
+
+func (s *stubImages) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.requests.Add(1)
+	body, ok := s.bodies[r.URL.String()]
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusNotFound
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader(body)), Header: http.Header{}, Request: r}, nil
+}
+
+func newTestStore(t *testing.T, bodies map[string][]byte) (*Store, *stubImages) {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s, err := NewStore(db, filepath.Join(t.TempDir(), "assets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubImages{bodies: bodies}
+	s.Client = &http.Client{Transport: stub}
+	return s, stub
+}
+
+func TestMirror(t *testing.T) {
+	s, stub := newTestStore(t, map[string][]byte{
+		"https://play-lh.googleusercontent.com/icon":  iconPNG,
+		"https://play-lh.googleusercontent.com/shot1": shotPNG,
+		"https://play-lh.googleusercontent.com/shot2": shotPNG, // same bytes, other URL
+		"https://play-lh.googleusercontent.com/page":  []byte("<html><body>not an image</body></html>"),
+	})
+	s.BaseURL = "https://scraper.example.com/"
+
+	app := &parser.App{
+		Icon:              "https://play-lh.googleusercontent.com/icon",
+		Screenshots:       []string{"https://play-lh.googleusercontent.com/shot1", "https://play-lh.googleusercontent.com/shot2"},
+		TabletScreenshots: []string{"https://example.com/tablet.png", "https://play-lh.googleusercontent.com/page"},
+		FeatureGraphic:    "http://play-lh.googleusercontent.com/icon",
+	}
+	got := s.Mirror(context.Background(), app)
+
+	if !strings.HasPrefix(got.Icon, "https://scraper.example.com/assets/") {
+		t.Errorf("icon = %q, want a local URL", got.Icon)
+	}
+	if got.Screenshots[0] != got.Screenshots[1] || got.Screenshots[0] == got.Icon {
+		t.Errorf("screenshots = %q, want one shared local URL", got.Screenshots)
+	}
+	// other hosts, plain http and non-images keep their URL
+	if got.TabletScreenshots[0] != app.TabletScreenshots[0] || got.TabletScreenshots[1] != app.TabletScreenshots[1] || got.FeatureGraphic != app.FeatureGraphic {
+		t.Errorf("unmirrorable URLs rewritten: %q, %q", got.TabletScreenshots, got.FeatureGraphic)
+	}
+	if app.Icon != "https://play-lh.googleusercontent.com/icon" || app.Screenshots[0] != "https://play-lh.googleusercontent.com/shot1" {
+		t.Errorf("Mirror changed its argument: %+v", app)
+	}
+
+	hash, ok := s.Hash(got.Screenshots[0])
+	if !ok {
+		t.Fatalf("Hash(%q) = false", got.Screenshots[0])
+	}
+	file, found, err := s.File(hash)
+	if err != nil || !found || file.ContentType != "image/png" || file.Size != int64(len(shotPNG)) {
+		t.Errorf("File(%s) = %+v, %v, %v", hash, file, found, err)
+	}
+
+	// mirroring again reuses the stored files
+	before := stub.requests.Load()
+	again := s.Mirror(context.Background(), app)
+	if again.Icon != got.Icon || again.Screenshots[1] != got.Screenshots[1] {
+		t.Errorf("second mirror = %+v", again)
+	}
+	if n := stub.requests.Load() - before; n != 1 { // only the non-image again
+		t.Errorf("second mirror made %d requests, want 1", n)
+	}
+	// and leaves local URLs alone
+	if local := s.Mirror(context.Background(), got); local.Icon != got.Icon {
+		t.Errorf("mirror of a mirrored app: icon = %q", local.Icon)
+	}
+}
+
+func TestFetchTooLarge(t *testing.T) {
+	s, _ := newTestStore(t, map[string][]byte{"https://lh3.googleusercontent.com/big": iconPNG})
+	s.MaxSize = 4
+	if _, err := s.Fetch(context.Background(), "https://lh3.googleusercontent.com/big"); err == nil {
+		t.Error("oversized image: no error")
+	}
+	if _, err := s.Fetch(context.Background(), "https://lh3.googleusercontent.com/missing"); err == nil {
+		t.Error("404: no error")
+	}
+}
+
+func TestServe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s, _ := newTestStore(t, map[string][]byte{"https://play-lh.googleusercontent.com/icon": iconPNG})
+	local, err := s.Fetch(context.Background(), "https://play-lh.googleusercontent.com/icon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET(Path+":hash", s.Serve)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get(local)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), iconPNG) {
+		t.Fatalf("GET %s = %d %q", local, w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if w.Header().Get("X-Content-Type-Options") != "nosniff" || !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("headers = %v", w.Header())
+	}
+
+	for _, path := range []string{Path + strings.Repeat("0", 64), Path + "..%2Fassets.db", Path + "abc"} {
+		if w := get(path); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, w.Code)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	s, _ := newTestStore(t, map[string][]byte{
+		"https://play-lh.googleusercontent.com/icon": iconPNG,
+		"https://play-lh.googleusercontent.com/shot": shotPNG,
+	})
+	ctx := context.Background()
+	icon, _ := s.Fetch(ctx, "https://play-lh.googleusercontent.com/icon")
+	shot, _ := s.Fetch(ctx, "https://play-lh.googleusercontent.com/shot")
+
+	// everything was just used: the grace period keeps it
+	res, err := s.Collect(map[string]bool{icon: true}, time.Hour)
+	if err != nil || res.Removed != 0 || res.Kept != 2 {
+		t.Fatalf("Collect within grace = %+v, %v", res, err)
+	}
+
+	res, err = s.Collect(map[string]bool{icon: true, "https://example.com/x.png": true}, 0)
+	if err != nil || res.Removed != 1 || res.Kept != 1 || res.Freed != int64(len(shotPNG)) {
+		t.Fatalf("Collect = %+v, %v", res, err)
+	}
+	shotHash, _ := s.Hash(shot)
+	if _, found, _ := s.File(shotHash); found {
+		t.Error("collected file still indexed")
+	}
+	if _, err := s.Open(shotHash); err == nil {
+		t.Error("collected file still on disk")
+	}
+	iconHash, _ := s.Hash(icon)
+	if f, err := s.Open(iconHash); err != nil {
+		t.Errorf("referenced file removed: %v", err)
+	} else {
+		f.Close()
+	}
+
+	// a collected URL is downloaded again
+	if again, err := s.Fetch(ctx, "https://play-lh.googleusercontent.com/shot"); err != nil || again != shot {
+		t.Errorf("Fetch after collect = %q, %v", again, err)
+	}
+}
@@ -0,0 +1,40 @@
+package assets
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Serve handles GET /assets/:hash. Files never change (the name is their
+// hash), so clients may cache them forever.
+func (s *Store) Serve(c *gin.Context) {
+	hash := c.Param("hash")
+	if !hashRe.MatchString(hash) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	file, found, err := s.File(hash)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	f, err := s.Open(hash)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	h := c.Writer.Header()
+	h.Set("Content-Type", file.ContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	h.Set("ETag", `"`+hash+`"`)
+	http.ServeContent(c.Writer, c.Request, "", file.CreatedAt, f)
+}
@@ -0,0 +1,49 @@
+// Command fakestore runs the fake Play Store standalone, for trying the app
+// offline:
+//
+//	go run ./cmd/fakestore -addr :9100 [-dir parser/fixtures]
+//	PLAYSTORE_BASE_URL=http://localhost:9100 go run .
+//
+// Faults can be set with flags or changed while it runs:
+//
+//	curl -X POST localhost:9100/_fake/faults -d '{"latency":"500ms","failNext":2,"failStatus":503}'
+//	curl -X POST localhost:9100/_fake/faults -d '{"throttleLimit":5,"throttleWindow":"10s"}'
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/fakestore"
+)
+
+func main() {
+	addr := flag.String("addr", ":9100", "listen address")
+	dir := flag.String("dir", "", "pages directory (default: the bundled pages)")
+	latency := flag.Duration("latency", 0, "delay every response")
+	failNext := flag.Int("fail", 0, "answer the first N requests with -fail-status")
+	failStatus := flag.Int("fail-status", http.StatusInternalServerError, "status used by -fail")
+	throttle := flag.Int("throttle", 0, "answer 429 after N requests per -window (0 = off)")
+	window := flag.Duration("window", 10*time.Second, "throttle window")
+	flag.Parse()
+
+	pages := fakestore.Pages()
+	if *dir != "" {
+		pages = os.DirFS(*dir)
+	}
+
+	s := fakestore.New(pages)
+	s.SetFaults(fakestore.Faults{
+		Latency:        *latency,
+		FailNext:       *failNext,
+		FailStatus:     *failStatus,
+		ThrottleLimit:  *throttle,
+		ThrottleWindow: *window,
+	})
+
+	log.Printf("fake Play Store on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
@@ -0,0 +1,111 @@
+// Command record-fixtures saves the raw Play Store detail page of each
+// package, as a candidate parser test fixture:
+//
+//	go run ./cmd/record-fixtures com.whatsapp org.telegram.messenger
+//	go run ./cmd/record-fixtures -list packages.txt -out /tmp/pages
+//
+// Pages go to parser/testdata/recorded by default, outside the embedded
+// parser/fixtures, so a recording never lands in the binary or the selftest
+// unreviewed. Copy a page worth keeping into parser/fixtures under a name
+// saying what it covers, then regenerate the expected output with
+//
+//	go test ./parser -run TestParseGolden -update
+//
+// and review the .golden diffs before committing them. Package names are
+// checked like the server does, since they become file names. Requests go
+// through the scraper's outbound rate limiter.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/scraper"
+)
+
+func main() {
+	out := flag.String("out", filepath.Join("parser", "testdata", "recorded"), "directory the pages are written to")
+	list := flag.String("list", "", "file with one package name per line (# starts a comment)")
+	timeout := flag.Duration("timeout", 2*time.Minute, "give up after this long")
+	flag.Parse()
+
+	pkgs := flag.Args()
+	if *list != "" {
+		more, err := readList(*list)
+		if err != nil {
+			log.Fatalf("read %s: %v", *list, err)
+		}
+		pkgs = append(pkgs, more...)
+	}
+	for i, raw := range pkgs {
+		pkg, err := scraper.ValidatePackage(raw)
+		if err != nil {
+			log.Fatalf("%q: %v", raw, err)
+		}
+		pkgs[i] = pkg
+	}
+	if len(pkgs) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: record-fixtures [-out dir] [-list file] package...")
+		os.Exit(2)
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	failed := 0
+	for _, pkg := range pkgs {
+		page, err := scraper.FetchPlayStorePage(ctx, pkg)
+		if errors.Is(err, scraper.ErrNotFound) {
+			fmt.Printf("%-40s not found, skipped\n", pkg)
+			continue
+		}
+		if err != nil {
+			fmt.Printf("%-40s FAILED: %v\n", pkg, err)
+			failed++
+			continue
+		}
+
+		path := filepath.Join(*out, pkg+".html")
+		if err := os.WriteFile(path, page, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%-40s %7d bytes -> %s\n", pkg, len(page), path)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func readList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pkgs []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line != "" {
+			pkgs = append(pkgs, line)
+		}
+	}
+	return pkgs, sc.Err()
+}
@@ -0,0 +1,67 @@
+// Command webhook-receiver is a local endpoint for trying out alert rules.
+// It verifies the signature of every delivery and prints the payload.
+//
+//	go run ./cmd/webhook-receiver -addr :9000 -secret s3cret -fail 2
+//
+// then create a rule with "webhookUrl": "http://localhost:9000/hook" and
+// POST /api/alerts/rules/<id>/test. -fail N answers 500 to the first N
+// requests so the retry path can be exercised.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/alerts"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	secret := flag.String("secret", "", "rule secret used to verify signatures (empty = don't verify)")
+	fail := flag.Int64("fail", 0, "answer 500 to the first N requests")
+	flag.Parse()
+
+	var received int64
+
+	http.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&received, 1)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if n <= *fail {
+			fmt.Printf("#%d failing on purpose (-fail %d)\n", n, *fail)
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+
+		ts := r.Header.Get(alerts.TimestampHeader)
+		sig := r.Header.Get(alerts.SignatureHeader)
+		if *secret != "" && !alerts.Verify(*secret, ts, body, sig) {
+			fmt.Printf("#%d REJECTED: bad signature %q\n", n, sig)
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err != nil {
+			pretty.Write(body)
+		}
+		fmt.Printf("#%d delivery %s (signature ok: %t)\n%s\n\n",
+			n, r.Header.Get(alerts.DeliveryHeader), *secret != "", pretty.String())
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("webhook receiver listening on %s/hook", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
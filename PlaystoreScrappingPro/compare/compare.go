@@ -0,0 +1,142 @@
+package compare
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+)
+
+// Column is one compared app (App is nil when fetching it failed)
+type Column struct {
+	Package string      `json:"package"`
+	App     *parser.App `json:"app,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Cell is one value in a row; Best marks the winning value(s) of the row
+type Cell struct {
+	Value string `json:"value"`
+	Best  bool   `json:"best"`
+}
+
+// Row aligns one field across all columns
+type Row struct {
+	Label string `json:"label"`
+	Cells []Cell `json:"cells"`
+}
+
+// Table is the full side-by-side comparison
+type Table struct {
+	Columns []Column `json:"columns"`
+	Rows    []Row    `json:"rows"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// ROWS — how each field is displayed and scored
+///////////////////////////////////////////////////////////////////////////////
+
+// rowSpec describes one row; score returns a comparable number for a value
+// (higher is better), ok=false when it can't be ranked ("Varies with device")
+type rowSpec struct {
+	label string
+	value func(app *parser.App) string
+	score func(value string) (float64, bool)
+}
+
+var rowSpecs = []rowSpec{
+	{"Rating", func(a *parser.App) string { return a.Rating }, parser.ParseNumber},
+	{"Total Ratings", func(a *parser.App) string { return a.RatingCount }, parser.ParseNumber},
+	{"Installs", func(a *parser.App) string { return a.Installs }, parser.ParseNumber},
+	{"Current Version", func(a *parser.App) string { return a.CurrentVersion }, nil},
+	{"Min Android", func(a *parser.App) string { return a.AndroidVersion }, lowerAndroid},
+	{"Last Updated", func(a *parser.App) string { return a.LastUpdated }, newerDate},
+	{"Price", func(a *parser.App) string { return a.Price.String() }, nil}, // currencies may differ
+	{"Ads", func(a *parser.App) string { return yesNo(a.AdSupported) }, noIsBetter},
+	{"In-App Purchases", func(a *parser.App) string { return yesNo(a.InAppPurchase) }, noIsBetter},
+}
+
+// Build aligns the columns into rows and highlights the best value per row
+func Build(columns []Column) Table {
+	t := Table{Columns: columns}
+
+	for _, spec := range rowSpecs {
+		row := Row{Label: spec.label, Cells: make([]Cell, len(columns))}
+		scores := make([]float64, len(columns))
+		ranked := make([]bool, len(columns))
+		best, haveBest := 0.0, false
+
+		for i, col := range columns {
+			if col.App == nil {
+				row.Cells[i].Value = "—"
+				continue
+			}
+			row.Cells[i].Value = spec.value(col.App)
+			if spec.score == nil {
+				continue
+			}
+			if s, ok := spec.score(row.Cells[i].Value); ok {
+				scores[i], ranked[i] = s, true
+				if !haveBest || s > best {
+					best, haveBest = s, true
+				}
+			}
+		}
+
+		// only highlight when there is something to beat
+		distinct := false
+		for i := range columns {
+			if ranked[i] && scores[i] != best {
+				distinct = true
+			}
+		}
+		if distinct {
+			for i := range columns {
+				row.Cells[i].Best = ranked[i] && scores[i] == best
+			}
+		}
+
+		t.Rows = append(t.Rows, row)
+	}
+
+	return t
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// VALUE PARSING — Play Store display strings to comparable numbers
+///////////////////////////////////////////////////////////////////////////////
+
+var androidRe = regexp.MustCompile(`(\d+)(?:\.(\d+))?`)
+
+// lowerAndroid ranks "5.0 and up" above "8.0 and up" (reaches more devices)
+func lowerAndroid(v string) (float64, bool) {
+	m := androidRe.FindStringSubmatch(v)
+	if m == nil {
+		return 0, false
+	}
+	major, _ := strconv.ParseFloat(m[1], 64)
+	minor, _ := strconv.ParseFloat(m[2], 64)
+	return -(major + minor/100), true
+}
+
+func newerDate(v string) (float64, bool) {
+	t, ok := parser.ParseDate(v)
+	if !ok {
+		return 0, false
+	}
+	return float64(t.Unix()), true
+}
+
+func noIsBetter(v string) (float64, bool) {
+	if v == "No" {
+		return 1, true
+	}
+	return 0, true
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,69 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+)
+
+func TestBuild(t *testing.T) {
+	table := Build([]Column{
+		{Package: "com.example.a", App: &parser.App{Rating: "4.1", RatingCount: "2K", Installs: "1M+", AndroidVersion: "8.0 and up", LastUpdated: "Mar 4, 2026", AdSupported: true}},
+		{Package: "com.example.b", App: &parser.App{Rating: "4.6", RatingCount: "900", Installs: "50M+", AndroidVersion: "5.0 and up", LastUpdated: "Jan 2, 2026", AdSupported: true}},
+		{Package: "com.example.missing", Error: "app not found"},
+	})
+
+	if len(table.Rows) != len(rowSpecs) {
+		t.Fatalf("%d rows, want %d", len(table.Rows), len(rowSpecs))
+	}
+	best := map[string][]bool{
+		"Rating":          {false, true, false},
+		"Total Ratings":   {true, false, false},
+		"Installs":        {false, true, false},
+		"Current Version": {false, false, false}, // not ranked
+		"Min Android":     {false, true, false},
+		"Last Updated":    {true, false, false},
+		"Ads":             {false, false, false}, // a tie is not highlighted
+	}
+	for _, row := range table.Rows {
+		if row.Cells[2].Value != "—" {
+			t.Errorf("%s: failed column shows %q", row.Label, row.Cells[2].Value)
+		}
+		want, ok := best[row.Label]
+		if !ok {
+			continue
+		}
+		for i, cell := range row.Cells {
+			if cell.Best != want[i] {
+				t.Errorf("%s: column %d best = %v, want %v", row.Label, i, cell.Best, want[i])
+			}
+		}
+	}
+}
+
+func TestScores(t *testing.T) {
+	if a, _ := lowerAndroid("5.0 and up"); a <= mustScore(t, lowerAndroid, "8.0 and up") {
+		t.Error("lowerAndroid does not rank 5.0 above 8.0")
+	}
+	if a, _ := lowerAndroid("4.4 and up"); a <= mustScore(t, lowerAndroid, "4.10 and up") {
+		t.Error("lowerAndroid does not rank 4.4 above 4.10")
+	}
+	if _, ok := lowerAndroid("Varies with device"); ok {
+		t.Error("lowerAndroid ranks Varies with device")
+	}
+	if _, ok := newerDate("N.A"); ok {
+		t.Error("newerDate ranks N.A")
+	}
+	if no, yes := mustScore(t, noIsBetter, "No"), mustScore(t, noIsBetter, "Yes"); no <= yes {
+		t.Error("noIsBetter does not rank No above Yes")
+	}
+}
+
+func mustScore(t *testing.T, score func(string) (float64, bool), v string) float64 {
+	t.Helper()
+	s, ok := score(v)
+	if !ok {
+		t.Fatalf("%q is not ranked", v)
+	}
+	return s
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/alerts"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/apiclient"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/apikeys"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/history"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/openapi"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+)
+
+// documented reports whether a route belongs in the OpenAPI document
+func documented(path string) bool {
+	for _, prefix := range []string{"/api/", "/admin/", "/healthz", "/readyz", "/selftest"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestOpenAPIRoutes checks the document describes exactly the JSON routes
+// the router serves
+func TestOpenAPIRoutes(t *testing.T) {
+	// with keys on, so the /admin routes exist
+	app := newTestAppAuth(t, &testAuth{admin: "admin-secret-0123456789"})
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	described := make(map[string]bool)
+	for _, op := range spec.Operations() {
+		described[op] = true
+	}
+
+	served := make(map[string]bool)
+	for _, r := range app.router.Routes() {
+		if r.Method == http.MethodHead || !documented(r.Path) {
+			continue
+		}
+		op := r.Method + " " + openapi.RoutePath(r.Path)
+		served[op] = true
+		if !described[op] {
+			t.Errorf("route %s is not in openapi.yaml", op)
+		}
+	}
+	var stale []string
+	for op := range described {
+		if !served[op] {
+			stale = append(stale, op)
+		}
+	}
+	sort.Strings(stale)
+	for _, op := range stale {
+		t.Errorf("openapi.yaml describes %s, which the router doesn't serve", op)
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	app := newTestApp(t)
+
+	for path, want := range map[string]string{
+		"/openapi.yaml": "openapi: 3.0.3",
+		"/openapi.json": `"openapi":"3.0.3"`,
+		"/docs":         "SwaggerUIBundle",
+	} {
+		w := app.get(path)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET %s: %d %.200s, want %q", path, w.Code, w.Body, want)
+		}
+	}
+}
+
+// contractTransport checks every response against the document
+type contractTransport struct {
+	t      *testing.T
+	spec   *openapi.Spec
+	checks atomic.Int32
+}
+
+func (ct *contractTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := ct.spec.ValidateResponse(req.Method, req.URL.Path, res.StatusCode, res.Header.Get("Content-Type"), body); err != nil {
+		ct.t.Errorf("contract: %v\n%.300s", err, body)
+	}
+	ct.checks.Add(1)
+	return res, nil
+}
+
+// TestOpenAPIContract drives the Go client against the real handlers,
+// checking every answer (successes and errors) against the document
+func TestOpenAPIContract(t *testing.T) {
+	const adminKey = "admin-secret-0123456789"
+	app := newTestAppAuth(t, &testAuth{
+		limits: apikeys.Limits{RateLimit: 1000, Burst: 1000},
+		anon:   apikeys.Limits{RateLimit: 0.001, Burst: 1},
+		admin:  adminKey,
+	})
+	app.runJobs(t)
+	srv := httptest.NewServer(app.router)
+	defer srv.Close()
+
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &contractTransport{t: t, spec: spec}
+	client := apiclient.New(srv.URL, adminKey)
+	client.HTTPClient = &http.Client{Transport: transport}
+	ctx := context.Background()
+
+	// apps
+	apk, err := client.App(ctx, "com.example.messenger")
+	if err != nil || apk.Title != "Example Messenger" {
+		t.Errorf("App = %+v, %v", apk, err)
+	}
+	if len(apk.TabletScreenshots) != 2 || apk.FeatureGraphic == "" || apk.Video == "" {
+		t.Errorf("media: tablet=%v feature=%q video=%q", apk.TabletScreenshots, apk.FeatureGraphic, apk.Video)
+	}
+	sized, err := client.AppImages(ctx, "com.example.messenger", parser.ImageSize{Width: 512, Height: 512}, parser.ImageSize{Width: 1080, Height: 1920})
+	if err != nil || !strings.HasSuffix(sized.Icon, "=w512-h512") || !strings.HasSuffix(sized.Screenshots[0], "=w1080-h1920") ||
+		!strings.HasSuffix(sized.FeatureGraphic, "=w1080-h1920") {
+		t.Errorf("AppImages = %+v, %v", sized, err)
+	}
+	if _, err := client.AppImages(ctx, "com.example.messenger", parser.ImageSize{Width: 5000, Height: 5000}, parser.ImageSize{}); apiclient.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("AppImages(too large): %v, want 400", err)
+	}
+	if _, diag, err := client.AppDiagnostics(ctx, "com.example.notes"); err != nil || diag == nil {
+		t.Errorf("AppDiagnostics: %v, %v", diag, err)
+	}
+	if _, err := client.App(ctx, "com.example.missing"); apiclient.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("App(missing): %v, want 404", err)
+	}
+	if _, err := client.App(ctx, "not a package"); apiclient.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("App(invalid): %v, want 400", err)
+	}
+	if reviews, err := client.Reviews(ctx, "com.example.messenger", 2); err != nil || len(reviews) == 0 {
+		t.Errorf("Reviews = %v, %v", reviews, err)
+	}
+	if results, err := client.Search(ctx, "messenger"); err != nil || len(results) == 0 {
+		t.Errorf("Search = %v, %v", results, err)
+	}
+	batch, err := client.Batch(ctx, []string{"com.example.notes", "com.example.missing"})
+	if err != nil || len(batch) != 2 || batch[0].App == nil || batch[1].Error == "" {
+		t.Errorf("Batch = %+v, %v", batch, err)
+	}
+	if table, err := client.Compare(ctx, []string{"com.example.messenger", "com.example.notes"}); err != nil || len(table.Columns) != 2 {
+		t.Errorf("Compare = %+v, %v", table, err)
+	}
+	if _, err := client.Compare(ctx, []string{"com.example.notes"}); apiclient.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Compare(1 app): %v, want 400", err)
+	}
+	var gql struct {
+		App struct{ Title string }
+	}
+	err = client.GraphQL(ctx, `query($p: String!) { app(package: $p) { title } }`, map[string]interface{}{"p": "com.example.notes"}, &gql)
+	if err != nil || gql.App.Title != "Pocket Notes" {
+		t.Errorf("GraphQL = %+v, %v", gql, err)
+	}
+
+	// history (the fetches above recorded snapshots)
+	if s, err := client.History(ctx, "com.example.messenger", apiclient.Range{}, history.Daily); err != nil || len(s.Points) == 0 {
+		t.Errorf("History = %+v, %v", s, err)
+	}
+	if _, err := client.Changes(ctx, "com.example.messenger", apiclient.Range{From: time.Now().Add(-time.Hour)}); err != nil {
+		t.Errorf("Changes: %v", err)
+	}
+
+	// watchlist
+	if _, err := client.Watch(ctx, "com.example.notes"); err != nil {
+		t.Errorf("Watch: %v", err)
+	}
+	if list, err := client.Watchlist(ctx); err != nil || len(list) != 1 {
+		t.Errorf("Watchlist = %+v, %v", list, err)
+	}
+	if err := client.Unwatch(ctx, "com.example.notes"); err != nil {
+		t.Errorf("Unwatch: %v", err)
+	}
+	if err := client.Unwatch(ctx, "com.example.notes"); apiclient.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Unwatch twice: %v, want 404", err)
+	}
+
+	// alerts
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer hook.Close()
+	rule, err := client.AddAlertRule(ctx, alerts.Rule{
+		Name: "low rating", Package: "com.example.notes", Type: alerts.TypeThreshold,
+		Field: "rating", Op: "<", Value: "3", WebhookURL: hook.URL,
+	})
+	if err != nil || rule.ID == "" {
+		t.Fatalf("AddAlertRule = %+v, %v", rule, err)
+	}
+	if _, err := client.AddAlertRule(ctx, alerts.Rule{Package: "com.example.notes"}); apiclient.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("AddAlertRule(invalid): %v, want 400", err)
+	}
+	if rules, err := client.AlertRules(ctx); err != nil || len(rules) != 1 {
+		t.Errorf("AlertRules = %+v, %v", rules, err)
+	}
+	if d, err := client.TestAlertRule(ctx, rule.ID); err != nil || !d.Success {
+		t.Errorf("TestAlertRule = %+v, %v", d, err)
+	}
+	if ds, err := client.AlertDeliveries(ctx, rule.ID, 10); err != nil || len(ds) != 1 {
+		t.Errorf("AlertDeliveries = %+v, %v", ds, err)
+	}
+	if err := client.DeleteAlertRule(ctx, rule.ID); err != nil {
+		t.Errorf("DeleteAlertRule: %v", err)
+	}
+
+	// parser rules
+	if rules, _, err := client.ParserRules(ctx); err != nil || rules == nil {
+		t.Errorf("ParserRules = %v, %v", rules, err)
+	}
+	if _, err := client.ReloadParserRules(ctx); apiclient.StatusCode(err) != http.StatusConflict {
+		t.Errorf("ReloadParserRules without a rules file: %v, want 409", err)
+	}
+
+	// jobs
+	job, err := client.SubmitJob(ctx, []string{"com.example.messenger", "com.example.missing"})
+	if err != nil || job.Total != 2 {
+		t.Fatalf("SubmitJob = %+v, %v", job, err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if job, err = client.WaitJob(waitCtx, job.ID, 10*time.Millisecond); err != nil || job.Succeeded != 1 || job.Failed != 1 {
+		t.Errorf("WaitJob = %+v, %v", job, err)
+	}
+	if items, err := client.JobResults(ctx, job.ID); err != nil || len(items) != 2 || items[0].App == nil {
+		t.Errorf("JobResults = %+v, %v", items, err)
+	}
+	if list, err := client.Jobs(ctx, "done"); err != nil || len(list) != 1 {
+		t.Errorf("Jobs = %+v, %v", list, err)
+	}
+	if _, err := client.CancelJob(ctx, job.ID); apiclient.StatusCode(err) != http.StatusConflict {
+		t.Errorf("CancelJob(finished): %v, want 409", err)
+	}
+	if _, err := client.Job(ctx, "nope"); apiclient.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Job(unknown): %v, want 404", err)
+	}
+
+	// admin
+	key, secret, err := client.CreateKey(ctx, apiclient.NewKey{Name: "ci", DailyQuota: 100})
+	if err != nil || secret == "" {
+		t.Fatalf("CreateKey = %+v, %v", key, err)
+	}
+	if keys, err := client.Keys(ctx); err != nil || len(keys) != 2 {
+		t.Errorf("Keys = %+v, %v", keys, err)
+	}
+	if _, err := client.Key(ctx, key.ID); err != nil {
+		t.Errorf("Key: %v", err)
+	}
+	ci := apiclient.New(srv.URL, secret)
+	ci.HTTPClient = client.HTTPClient
+	if _, err := ci.Keys(ctx); apiclient.StatusCode(err) != http.StatusForbidden {
+		t.Errorf("Keys as non-admin: %v, want 403", err)
+	}
+	if _, err := client.RevokeKey(ctx, key.ID); err != nil {
+		t.Errorf("RevokeKey: %v", err)
+	}
+	if _, err := ci.App(ctx, "com.example.notes"); apiclient.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("App with a revoked key: %v, want 401", err)
+	}
+
+	if transport.checks.Load() == 0 {
+		t.Error("no responses were checked")
+	}
+}
@@ -0,0 +1,242 @@
+package diff
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+)
+
+// Change is one field that differs between two parses of the same app
+type Change struct {
+	Field   string   `json:"field"` // json name of the parser.App field
+	Label   string   `json:"label"`
+	Old     string   `json:"old,omitempty"`
+	New     string   `json:"new,omitempty"`
+	Delta   float64  `json:"delta,omitempty"`   // numeric fields: new - old
+	Added   []string `json:"added,omitempty"`   // list fields: items only in new
+	Removed []string `json:"removed,omitempty"` // list fields: items only in old
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// FIELDS — compared in display order
+///////////////////////////////////////////////////////////////////////////////
+
+type field struct {
+	name    string
+	label   string
+	value   func(app *parser.App) string
+	numeric bool
+}
+
+var fields = []field{
+	{"title", "Title", func(a *parser.App) string { return a.Title }, false},
+	{"icon", "Icon", func(a *parser.App) string { return a.Icon }, false},
+	{"developer", "Developer", func(a *parser.App) string { return a.Developer }, false},
+	{"developerEmail", "Developer Email", func(a *parser.App) string { return a.DeveloperEmail }, false},
+	{"developerWebsite", "Developer Website", func(a *parser.App) string { return a.DeveloperWebsite }, false},
+	{"genre", "Category", func(a *parser.App) string { return a.Category }, false},
+	{"rating", "Rating", func(a *parser.App) string { return a.Rating }, true},
+	{"ratingCount", "Total Ratings", func(a *parser.App) string { return a.RatingCount }, true},
+	{"ratingHistogram", "Rating Distribution", func(a *parser.App) string { return a.RatingHistogram.String() }, false},
+	{"installs", "Installs", func(a *parser.App) string { return a.Installs }, false},
+	{"free", "Free", func(a *parser.App) string { return strconv.FormatBool(a.Free) }, false},
+	{"price", "Price", func(a *parser.App) string { return a.Price.String() }, false},
+	{"inAppPrice", "In-App Price Range", func(a *parser.App) string { return a.InAppPrice.String() }, false},
+	{"adSupported", "Ad Supported", func(a *parser.App) string { return strconv.FormatBool(a.AdSupported) }, false},
+	{"InAppPurchase", "In-App Purchases", func(a *parser.App) string { return strconv.FormatBool(a.InAppPurchase) }, false},
+	{"updated", "Last Updated", func(a *parser.App) string { return a.LastUpdated }, false},
+	{"version", "Current Version", func(a *parser.App) string { return a.CurrentVersion }, false},
+	{"androidVersion", "Android Version", func(a *parser.App) string { return a.AndroidVersion }, false},
+	{"summary", "Short Description", func(a *parser.App) string { return a.ShortDesc }, false},
+	{"description", "Full Description", func(a *parser.App) string { return a.Description }, false},
+	{"featureGraphic", "Feature Graphic", func(a *parser.App) string { return a.FeatureGraphic }, false},
+	{"video", "Promo Video", func(a *parser.App) string { return a.Video }, false},
+}
+
+// Compare lists every field that differs between old and new. A nil old
+// (first snapshot of a package) yields no changes.
+func Compare(old, new *parser.App) []Change {
+	if old == nil || new == nil {
+		return nil
+	}
+
+	var changes []Change
+
+	for _, f := range fields {
+		o, n := f.value(old), f.value(new)
+		if o == n {
+			continue
+		}
+		ch := Change{Field: f.name, Label: f.label, Old: o, New: n}
+		if f.numeric {
+			of, ok1 := parser.ParseNumber(o)
+			nf, ok2 := parser.ParseNumber(n)
+			if ok1 && ok2 {
+				ch.Delta = math.Round((nf-of)*100) / 100
+			}
+		}
+		changes = append(changes, ch)
+	}
+
+	// Screenshots: report what was added/removed, not just "changed"
+	for _, l := range []struct {
+		field, label string
+		old, new     []string
+	}{
+		{"screenshots", "Screenshots", old.Screenshots, new.Screenshots},
+		{"tabletScreenshots", "Tablet Screenshots", old.TabletScreenshots, new.TabletScreenshots},
+	} {
+		added, removed := listDiff(l.old, l.new)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, Change{
+				Field:   l.field,
+				Label:   l.label,
+				Added:   added,
+				Removed: removed,
+			})
+		}
+	}
+
+	return changes
+}
+
+func listDiff(old, new []string) (added, removed []string) {
+	inOld := make(map[string]bool, len(old))
+	for _, v := range old {
+		inOld[v] = true
+	}
+	inNew := make(map[string]bool, len(new))
+	for _, v := range new {
+		inNew[v] = true
+		if !inOld[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range old {
+		if !inNew[v] {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// WORD DIFF — inline view of long text edits (descriptions)
+///////////////////////////////////////////////////////////////////////////////
+
+// Op is the kind of a Segment
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Segment is a run of words with the same Op
+type Segment struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxCells bounds the O(n*m) LCS table (about 2MB). Only the part between
+// the common prefix and suffix goes into it; a bigger middle diffs as one
+// replacement.
+const maxCells = 250_000
+
+// Words returns a word-level diff of old -> new
+func Words(old, new string) []Segment {
+	a, b := strings.Fields(old), strings.Fields(new)
+
+	// words are gathered per run and joined once, not appended to a string
+	type run struct {
+		op    Op
+		words []string
+	}
+	var runs []run
+	emit := func(op Op, word string) {
+		if n := len(runs); n > 0 && runs[n-1].op == op {
+			runs[n-1].words = append(runs[n-1].words, word)
+			return
+		}
+		runs = append(runs, run{op, []string{word}})
+	}
+
+	// edits are usually local: keep the common ends out of the table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, w := range a[:prefix] {
+		emit(Equal, w)
+	}
+	middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], emit)
+	for _, w := range a[len(a)-suffix:] {
+		emit(Equal, w)
+	}
+
+	var segs []Segment
+	for _, r := range runs {
+		segs = append(segs, Segment{r.op, strings.Join(r.words, " ")})
+	}
+	return segs
+}
+
+// middle emits the diff of a -> b, from their LCS when the table fits in
+// maxCells
+func middle(a, b []string, emit func(op Op, word string)) {
+	if (len(a)+1)*(len(b)+1) > maxCells {
+		for _, w := range a {
+			emit(Delete, w)
+		}
+		for _, w := range b {
+			emit(Insert, w)
+		}
+		return
+	}
+
+	// lcs[i][j] = length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			emit(Equal, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			emit(Delete, a[i])
+			i++
+		default:
+			emit(Insert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		emit(Delete, a[i])
+	}
+	for ; j < len(b); j++ {
+		emit(Insert, b[j])
+	}
+}
@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []Segment
+	}{
+		{"equal", "a b c", "a  b\nc", []Segment{{Equal, "a b c"}}},
+		{"both empty", "", "", nil},
+		{"added", "", "new text", []Segment{{Insert, "new text"}}},
+		{"removed", "old text", "", []Segment{{Delete, "old text"}}},
+		{"word replaced", "the quick fox", "the slow fox", []Segment{{Equal, "the"}, {Delete, "quick"}, {Insert, "slow"}, {Equal, "fox"}}},
+		{"insert in the middle", "a b d", "a b c d", []Segment{{Equal, "a b"}, {Insert, "c"}, {Equal, "d"}}},
+		{"moved word", "x a b", "a b x", []Segment{{Delete, "x"}, {Equal, "a b"}, {Insert, "x"}}},
+		{"repeated words", "a a a", "a a", []Segment{{Equal, "a a"}, {Delete, "a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Words(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWordsLong(t *testing.T) {
+	words := func(prefix string, n int) string {
+		w := make([]string, n)
+		for i := range w {
+			w[i] = prefix
+		}
+		return strings.Join(w, " ")
+	}
+
+	// a small edit in a long text still diffs word by word: the common ends
+	// stay out of the LCS table
+	long := words("same", 20000)
+	got := Words(long+" old "+long, long+" new "+long)
+	want := []Segment{{Equal, long}, {Delete, "old"}, {Insert, "new"}, {Equal, long}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("small edit in a long text: %d segments, want %d", len(got), len(want))
+	}
+
+	// a middle too big for the table is one replacement
+	got = Words("start "+words("a", 1000)+" end", "start "+words("b", 1000)+" end")
+	want = []Segment{{Equal, "start"}, {Delete, words("a", 1000)}, {Insert, words("b", 1000)}, {Equal, "end"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("big rewrite: got %d segments, want delete + insert between the common ends", len(got))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old := &parser.App{
+		Title: "Notes", Rating: "4.1", RatingCount: "900", Installs: "1M+",
+		Screenshots: []string{"a.png", "b.png"},
+	}
+	new := &parser.App{
+		Title: "Pocket Notes", Rating: "4.35", RatingCount: "1000", Installs: "1M+",
+		Screenshots: []string{"b.png", "c.png"},
+	}
+
+	want := []Change{
+		{Field: "title", Label: "Title", Old: "Notes", New: "Pocket Notes"},
+		{Field: "rating", Label: "Rating", Old: "4.1", New: "4.35", Delta: 0.25},
+		{Field: "ratingCount", Label: "Total Ratings", Old: "900", New: "1000", Delta: 100},
+		{Field: "screenshots", Label: "Screenshots", Added: []string{"c.png"}, Removed: []string{"a.png"}},
+	}
+	if got := Compare(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare:\n got %+v\nwant %+v", got, want)
+	}
+
+	if got := Compare(old, old); len(got) != 0 {
+		t.Errorf("Compare(old, old) = %+v, want no changes", got)
+	}
+	if got := Compare(nil, new); got != nil {
+		t.Errorf("Compare(nil, new) = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,1080 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/apikeys"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/assets"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/fakestore"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/grpcapi/playstorepb"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/scraper"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testApp is the whole Gin app wired to a fake Play Store
+type testApp struct {
+	router *gin.Engine
+	store  *fakestore.Server
+	db     *storage.DB
+}
+
+// testAuth turns API key checks on for a test app
+type testAuth struct {
+	limits, anon apikeys.Limits
+	keys         map[string]string
+	admin        string
+}
+
+func newTestApp(t *testing.T) *testApp {
+	return newTestAppAuth(t, nil)
+}
+
+func newTestAppAuth(t *testing.T, auth *testAuth) *testApp {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	if err := logging.Setup(io.Discard, "", "error"); err != nil {
+		t.Fatal(err)
+	}
+
+	store := fakestore.New(nil)
+	srv := store.Start()
+	t.Cleanup(srv.Close)
+
+	if err := scraper.SetBaseURL(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	scraper.SetRateLimit(1000, 1000)
+	t.Cleanup(func() {
+		scraper.SetBaseURL(scraper.DefaultBaseURL)
+		scraper.SetRateLimit(scraper.DefaultRateLimit, scraper.DefaultRateBurst)
+	})
+
+	oldDelay, oldMax := retryDelay, maxRetryAfter
+	retryDelay, maxRetryAfter = 10*time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() { retryDelay, maxRetryAfter = oldDelay, oldMax })
+
+	cacheLock.Lock()
+	Cache = make(map[string]CacheEntry)
+	ReviewsCache = make(map[string]ReviewsEntry)
+	SearchCache = make(map[string]SearchEntry)
+	cacheLock.Unlock()
+
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := openStores(db); err != nil {
+		t.Fatal(err)
+	}
+	apiKeys, apiGuard = nil, nil
+	assetStore = nil
+	if auth != nil {
+		if err := setupAPIKeys(db, auth.limits, auth.anon, auth.keys, auth.admin); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &testApp{router: newRouter(db, time.Hour), store: store, db: db}
+}
+
+// runJobs processes batch jobs until the test ends
+func (a *testApp) runJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		jobManager.Run(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+func (a *testApp) get(path string) *httptest.ResponseRecorder {
+	return a.do(http.MethodGet, path, "", "")
+}
+
+// postForm posts an HTML form, optionally through a proxy header
+func (a *testApp) postForm(path string, form url.Values, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+// do sends a request with an optional API key and JSON body
+func (a *testApp) do(method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(apikeys.Header, key)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestE2EAppInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(s *fakestore.Server)
+		pkg      string
+		status   int
+		contains string
+		requests int // upstream requests expected
+	}{
+		{"json-ld page", nil, "com.example.messenger", 200, `"title":"Example Messenger"`, 1},
+		{"html fallbacks", nil, "com.example.notes", 200, `"installs":"1,000,000+"`, 1},
+		{"rating histogram", nil, "com.example.messenger", 200, `"ratingHistogram":[{"stars":5,"percent":72},{"stars":4,"percent":12}`, 1},
+		{"discounted price", nil, "in.example.cricket", 200, `"price":{"amount":49,"currency":"INR","display":"₹49.00","original":99,"saleEnds":"2026-10-31T00:00:00Z","country":"US"}`, 1},
+		{"in-app price range", nil, "com.example.messenger", 200, `"inAppPrice":{"min":0.99,"max":49.99,"currency":"USD"`, 1},
+		{"not found is final", nil, "com.example.missing", 404, "app not found", 1},
+		{"retries server errors",
+			func(s *fakestore.Server) { s.FailNext(2, http.StatusServiceUnavailable) },
+			"com.example.messenger", 200, `"title":"Example Messenger"`, 3},
+		{"gives up after three attempts",
+			func(s *fakestore.Server) { s.FailNext(5, http.StatusInternalServerError) },
+			"com.example.messenger", 502, "failed to reach Google Play", 3},
+		{"throttled",
+			func(s *fakestore.Server) { s.Throttle(1, time.Minute) },
+			"", 502, "failed to reach Google Play", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			if tt.setup != nil {
+				tt.setup(app.store)
+			}
+
+			pkg := tt.pkg
+			if pkg == "" {
+				// use up the throttle window, then ask for another app
+				app.get("/api/app-info?package=com.example.notes")
+				pkg = "com.example.messenger"
+			}
+
+			w := app.get("/api/app-info?package=" + pkg)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.status, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("body %s does not contain %s", w.Body, tt.contains)
+			}
+			if got := app.store.Requests(); got != tt.requests {
+				t.Errorf("upstream requests = %d, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestE2ECountry(t *testing.T) {
+	app := newTestApp(t)
+	priceIn := func(path string) *parser.App {
+		t.Helper()
+		w := app.get(path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d (%s)", path, w.Code, w.Body)
+		}
+		var got parser.App
+		json.Unmarshal(w.Body.Bytes(), &got)
+		if got.Price == nil || got.InAppPrice == nil {
+			t.Fatalf("%s: no prices in %s", path, w.Body)
+		}
+		return &got
+	}
+
+	// "$" is the Canadian dollar on the CA storefront
+	if got := priceIn("/api/app-info?package=com.example.messenger&country=ca"); got.Price.Country != "CA" || got.InAppPrice.Currency != "CAD" {
+		t.Errorf("CA: price = %+v, in-app = %+v", got.Price, got.InAppPrice)
+	}
+	if latest, _ := historyStore.Latest("com.example.messenger"); latest != nil {
+		t.Error("another storefront was recorded in history")
+	}
+
+	// the default storefront is cached apart
+	if got := priceIn("/api/app-info?package=com.example.messenger"); got.Price.Country != "US" || got.InAppPrice.Currency != "USD" {
+		t.Errorf("US: price = %+v, in-app = %+v", got.Price, got.InAppPrice)
+	}
+	priceIn("/api/app-info?package=com.example.messenger&country=CA")
+	if n := app.store.Requests(); n != 2 {
+		t.Errorf("upstream requests = %d, want 2", n)
+	}
+
+	for _, country := range []string{"usa", "1n", "u"} {
+		if w := app.get("/api/app-info?package=com.example.messenger&country=" + country); w.Code != http.StatusBadRequest {
+			t.Errorf("country=%s: status = %d, want 400", country, w.Code)
+		}
+	}
+}
+
+func TestE2ECacheAndLatency(t *testing.T) {
+	app := newTestApp(t)
+	app.store.SetLatency(100 * time.Millisecond)
+
+	start := time.Now()
+	if w := app.get("/api/app-info?package=com.example.messenger"); w.Code != 200 {
+		t.Fatalf("first request: %d %s", w.Code, w.Body)
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("first request took %s, want the injected latency", d)
+	}
+
+	start = time.Now()
+	if w := app.get("/api/app-info?package=com.example.messenger"); w.Code != 200 {
+		t.Fatalf("second request: %d %s", w.Code, w.Body)
+	}
+	if d := time.Since(start); d >= 100*time.Millisecond {
+		t.Errorf("cached request took %s", d)
+	}
+	if got := app.store.Requests(); got != 1 {
+		t.Errorf("upstream requests = %d, want 1 (second one cached)", got)
+	}
+}
+
+func TestE2EPages(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/", 200, "<form"},
+		{"/app-info?package=com.example.notes", 200, "Pocket Notes"},
+		{"/app-info?package=com.example.notes&debug=1", 200, "Extraction details"},
+		{"/app-info?package=com.example.notes", 200, "72.9% (9001)"},
+		{"/app-info?package=com.example.messenger", 200, "width:17%"},
+		{"/app-info?package=in.example.cricket", 200, "Price: 49 INR (was 99 INR until Oct 31, 2026)"},
+		{"/app-info?package=com.example.messenger&country=CA", 200, "(0.99–49.99 CAD per item)"},
+		{"/app-info?package=com.example.messenger&country=USA", 400, "two-letter"},
+		{"/app-info?package=com.example.missing", 404, "app not found"},
+		{"/app-info?package=bad", 400, "invalid package"},
+		{"/api/app-info?package=com.example.notes&format=csv", 200, "Pocket Notes"},
+		{"/api/batch?packages=com.example.notes,com.example.missing&format=csv", 200, "app not found"},
+		{"/compare?packages=com.example.notes,com.example.messenger", 200, "Example Messenger"},
+		{"/changes?package=com.example.notes", 200, "No changes recorded"},
+		{"/healthz", 200, `"ok"`},
+		{"/readyz", 200, `"templates"`},
+		{"/selftest", 200, `"failed":0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := app.get(tt.path)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%.300s)", w.Code, tt.status, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("body does not contain %q: %.500s", tt.contains, w.Body)
+			}
+		})
+	}
+}
+
+func TestE2EAPIKeys(t *testing.T) {
+	const (
+		adminKey = "admin-secret-0123456789"
+		ciKey    = "ci-secret-0123456789ab"
+		opsKey   = "ops-secret-0123456789ab"
+	)
+	app := newTestAppAuth(t, &testAuth{
+		limits: apikeys.Limits{RateLimit: 100, Burst: 100, DailyQuota: 3},
+		anon:   apikeys.Limits{RateLimit: 0.001, Burst: 1},
+		keys:   map[string]string{"ci": ciKey, "ops": opsKey},
+		admin:  adminKey,
+	})
+	const page = "/api/app-info?package=com.example.notes"
+
+	if w := app.do("GET", page, "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no key: status = %d, want 401", w.Code)
+	}
+	if w := app.do("GET", page, "wrong-key-0123456789", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad key: status = %d, want 401", w.Code)
+	}
+
+	// daily quota of 3
+	for i := 1; i <= 3; i++ {
+		if w := app.do("GET", page, ciKey, ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d (%s)", i, w.Code, w.Body)
+		}
+	}
+	w := app.do("GET", page, ciKey, "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("over quota: status = %d Retry-After=%q, want 429 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// admin endpoints
+	if w := app.do("GET", "/admin/keys", ciKey, ""); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin on /admin/keys: status = %d, want 403", w.Code)
+	}
+	w = app.do("POST", "/admin/keys", adminKey, `{"name":"burst","rateLimit":0.001,"burst":1}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create key: status = %d (%s)", w.Code, w.Body)
+	}
+	var created struct {
+		Key    apikeys.Status `json:"key"`
+		Secret string         `json:"secret"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.Secret == "" {
+		t.Fatalf("create key response %s: %v", w.Body, err)
+	}
+
+	// rate limit: burst of 1, then 429 with Retry-After
+	if w := app.do("GET", page, created.Secret, ""); w.Code != http.StatusOK {
+		t.Fatalf("new key: status = %d (%s)", w.Code, w.Body)
+	}
+	w = app.do("GET", page, created.Secret, "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("over rate limit: status = %d Retry-After=%q, want 429 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	w = app.do("GET", "/admin/keys/"+created.Key.ID, adminKey, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"today":1`) {
+		t.Errorf("key usage: %d %s", w.Code, w.Body)
+	}
+
+	if w := app.do("DELETE", "/admin/keys/"+created.Key.ID, adminKey, ""); w.Code != http.StatusOK {
+		t.Errorf("revoke: status = %d (%s)", w.Code, w.Body)
+	}
+	if w := app.do("GET", page, created.Secret, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status = %d, want 401", w.Code)
+	}
+	if w := app.do("DELETE", "/admin/keys/cfg-ci", adminKey, ""); w.Code != http.StatusConflict {
+		t.Errorf("revoke config key: status = %d, want 409", w.Code)
+	}
+
+	// an explicit 0 quota is unlimited, an omitted one takes the default
+	for body, want := range map[string]string{
+		`{"name":"unlimited","dailyQuota":0}`: `"dailyQuota":0`,
+		`{"name":"default"}`:                  `"dailyQuota":3`,
+	} {
+		if w := app.do("POST", "/admin/keys", adminKey, body); w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("create %s: %d %s, want %s", body, w.Code, w.Body, want)
+		}
+	}
+
+	// HTML pages stay open to anonymous visitors, limited per IP
+	if w := app.get("/app-info?package=com.example.notes"); w.Code != http.StatusOK {
+		t.Errorf("anonymous page: status = %d", w.Code)
+	}
+	if w := app.get("/app-info?package=com.example.notes"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("anonymous page over limit: status = %d, want 429", w.Code)
+	}
+	// a forged X-Forwarded-For is not another client
+	req := httptest.NewRequest(http.MethodGet, "/app-info?package=com.example.notes", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	w = httptest.NewRecorder()
+	app.router.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("anonymous page with X-Forwarded-For: status = %d, want 429", w.Code)
+	}
+
+	// watching an app takes a key, posted with the form
+	if w := app.postForm("/watchlist", url.Values{"package": {"com.example.notes"}}, "198.51.100.1"); w.Code != http.StatusUnauthorized {
+		t.Errorf("watch without a key: status = %d, want 401", w.Code)
+	}
+	if w := app.postForm("/watchlist/remove", url.Values{"package": {"com.example.notes"}}, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("unwatch without a key: status = %d, want 401", w.Code)
+	}
+	if w := app.postForm("/watchlist", url.Values{"package": {"com.example.notes"}, apikeys.FormField: {opsKey}}, ""); w.Code != http.StatusSeeOther {
+		t.Errorf("watch with a key: status = %d (%s)", w.Code, w.Body)
+	}
+
+	// alert rules hold webhook URLs and secrets: admin only
+	if w := app.do("GET", "/api/alerts/rules", opsKey, ""); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin on /api/alerts/rules: status = %d, want 403", w.Code)
+	}
+	rule := `{"name":"drop","package":"*","type":"threshold","field":"rating","op":"<","value":"4","webhookUrl":"http://localhost:9/hook","secret":"hook-s3cret"}`
+	if w := app.do("POST", "/api/alerts/rules", adminKey, rule); w.Code != http.StatusCreated || strings.Contains(w.Body.String(), "hook-s3cret") {
+		t.Errorf("add rule: status = %d (%s), want 201 without the secret", w.Code, w.Body)
+	}
+	if w := app.do("GET", "/api/alerts/rules", adminKey, ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"drop"`) || strings.Contains(w.Body.String(), "hook-s3cret") {
+		t.Errorf("admin on /api/alerts/rules: status = %d (%s), want the rule without its secret", w.Code, w.Body)
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := checkCache(ctx); err != nil {
+		t.Fatalf("free lock: %v", err)
+	}
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if err := checkCache(ctx); err == nil {
+		t.Error("checkCache passes while a writer holds the lock")
+	}
+}
+
+func TestE2EParserRulesReload(t *testing.T) {
+	app := newTestApp(t)
+	bundled := parser.CurrentRules()
+	t.Cleanup(func() {
+		rulesPath = ""
+		parser.SetRules(bundled)
+	})
+
+	if w := app.do("POST", "/api/parser/rules/reload", "", ""); w.Code != http.StatusConflict {
+		t.Errorf("no rules file: status = %d, want 409", w.Code)
+	}
+
+	rulesPath = filepath.Join(t.TempDir(), "rules.json")
+	next := parser.DefaultRules()
+	next.Version = 99
+	data, err := json.Marshal(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rulesPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if w := app.do("POST", "/api/parser/rules/reload", "", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"version":99`) {
+		t.Fatalf("reload: %d %s", w.Code, w.Body)
+	}
+	loaded := parser.CurrentRules()
+	if loaded.Version != 99 {
+		t.Fatalf("rules in use: version %d, want 99", loaded.Version)
+	}
+
+	// a broken file is refused and the rules in use stay
+	for _, broken := range []string{
+		`{"version": 100, "fields": [`,
+		`{"version": 100, "fields": [{"field": "title", "strategies": [{"kind": "script", "keywords": ["x"], "regex": "("}]}]}`,
+		`{"version": 100, "fields": [{"field": "title", "strategies": [{"kind": "xpath"}]}]}`,
+	} {
+		if err := os.WriteFile(rulesPath, []byte(broken), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if w := app.do("POST", "/api/parser/rules/reload", "", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("reload %s: status = %d, want 400", broken, w.Code)
+		}
+		if parser.CurrentRules() != loaded {
+			t.Fatalf("a failed reload replaced the rules in use")
+		}
+	}
+	if w := app.get("/api/app-info?package=com.example.notes"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Pocket Notes") {
+		t.Errorf("parse after failed reloads: %d %.300s", w.Code, w.Body)
+	}
+}
+
+func TestE2EBatchPost(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name, path, body string
+		status           int
+		contains         string
+	}{
+		{"query, no body", "/api/batch?packages=com.example.notes,com.example.messenger", "", 200, "Example Messenger"},
+		{"body", "/api/batch", `{"packages":["com.example.notes"]}`, 200, "Pocket Notes"},
+		{"query and body", "/api/batch?packages=com.example.notes", `{"packages":["com.example.messenger"]}`, 200, "Example Messenger"},
+		{"neither", "/api/batch", "", 400, "at least one package"},
+		{"bad JSON", "/api/batch?packages=com.example.notes", `{"packages":`, 400, "invalid JSON body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := app.do("POST", tt.path, "", tt.body)
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("status = %d (%.300s), want %d containing %q", w.Code, w.Body, tt.status, tt.contains)
+			}
+		})
+	}
+}
+
+func TestE2EPackageQuota(t *testing.T) {
+	const (
+		adminKey = "admin-secret-0123456789"
+		ciKey    = "ci-secret-0123456789ab"
+	)
+	app := newTestAppAuth(t, &testAuth{
+		limits: apikeys.Limits{RateLimit: 100, Burst: 100, DailyQuota: 5},
+		keys:   map[string]string{"ci": ciKey},
+		admin:  adminKey,
+	})
+	usage := func() string {
+		return app.do("GET", "/admin/keys/cfg-ci", adminKey, "").Body.String()
+	}
+
+	// a batch of 3 takes 3
+	w := app.do("GET", "/api/batch?packages=com.example.messenger,com.example.notes,in.example.cricket", ciKey, "")
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Remaining") != "2" {
+		t.Fatalf("batch: status = %d remaining=%q (%s)", w.Code, w.Header().Get("X-RateLimit-Remaining"), w.Body)
+	}
+	if got := usage(); !strings.Contains(got, `"today":3`) {
+		t.Errorf("usage after batch: %s, want today 3", got)
+	}
+
+	// a job bigger than what's left is refused whole
+	w = app.do("POST", "/api/jobs", ciKey, `{"packages":["com.example.messenger","com.example.notes","in.example.cricket"]}`)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("job over quota: status = %d Retry-After=%q (%s), want 429", w.Code, w.Header().Get("Retry-After"), w.Body)
+	}
+	if got := usage(); !strings.Contains(got, `"today":4`) {
+		t.Errorf("usage after refused job: %s, want today 4", got)
+	}
+
+	// so is a GraphQL apps() query
+	w = app.do("POST", "/api/graphql", ciKey, `{"query":"{ apps(packages: [\"com.example.messenger\", \"com.example.notes\"]) { title } }"}`)
+	if !strings.Contains(w.Body.String(), "daily quota") || strings.Contains(w.Body.String(), "Pocket Notes") {
+		t.Errorf("graphql apps over quota: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestE2EJobs(t *testing.T) {
+	app := newTestApp(t)
+	app.runJobs(t)
+
+	if w := app.do("POST", "/api/jobs", "", `{"packages":[]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("empty job: status = %d, want 400", w.Code)
+	}
+
+	w := app.do("POST", "/api/jobs", "", `{"packages":["com.example.messenger","com.example.missing","com.example.notes"]}`)
+	if w.Code != http.StatusAccepted || w.Header().Get("Location") == "" {
+		t.Fatalf("submit: status = %d Location=%q (%s)", w.Code, w.Header().Get("Location"), w.Body)
+	}
+	var job jobStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if job.Total != 3 || job.Pending != 3 {
+		t.Errorf("submitted job = %+v, want 3 pending packages", job)
+	}
+
+	// poll until finished
+	deadline := time.Now().Add(5 * time.Second)
+	for !job.Finished() {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish: %+v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		w = app.get("/api/jobs/" + job.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status: %d %s", w.Code, w.Body)
+		}
+		job = jobStatus{}
+		json.Unmarshal(w.Body.Bytes(), &job)
+	}
+	if job.Status != "done" || job.Succeeded != 2 || job.Failed != 1 || len(job.Items) != 3 {
+		t.Errorf("finished job = %+v, want done with 2 succeeded and 1 failed", job)
+	}
+	if job.Items[1].Status != "failed" || !strings.Contains(job.Items[1].Error, "not found") {
+		t.Errorf("missing package progress = %+v", job.Items[1])
+	}
+
+	w = app.get("/api/jobs/" + job.ID + "/results")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"title":"Example Messenger"`) {
+		t.Errorf("results: %d %.300s", w.Code, w.Body)
+	}
+	w = app.get("/api/jobs/" + job.ID + "/results?format=csv")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Pocket Notes") || !strings.Contains(w.Body.String(), "not found") {
+		t.Errorf("csv results: %d %.300s", w.Code, w.Body)
+	}
+
+	if w := app.get("/api/jobs?status=done"); !strings.Contains(w.Body.String(), job.ID) {
+		t.Errorf("job list does not include %s: %s", job.ID, w.Body)
+	}
+	if w := app.do("POST", "/api/jobs/"+job.ID+"/cancel", "", ""); w.Code != http.StatusConflict {
+		t.Errorf("cancel finished job: status = %d, want 409", w.Code)
+	}
+	if w := app.get("/api/jobs/nope"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: status = %d, want 404", w.Code)
+	}
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// stream reads every server-sent event of a GET over a real connection
+// (gin's streaming needs a CloseNotifier, which the recorder lacks)
+func (a *testApp) stream(t *testing.T, path string) []sseEvent {
+	t.Helper()
+	srv := httptest.NewServer(a.router)
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); res.StatusCode != http.StatusOK || !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("status = %d, Content-Type = %q", res.StatusCode, ct)
+	}
+
+	var events []sseEvent
+	var ev sseEvent
+	sc := bufio.NewScanner(res.Body)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			ev.name = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			ev.data += strings.TrimPrefix(line, "data:")
+		case line == "" && ev.name != "":
+			events = append(events, ev)
+			ev = sseEvent{}
+		}
+	}
+	return events
+}
+
+// eventNames lists the events' names, collapsing runs of "package"
+func eventNames(events []sseEvent) (names []string, packages int) {
+	for _, ev := range events {
+		if ev.name == "package" {
+			packages++
+			if len(names) > 0 && names[len(names)-1] == "package" {
+				continue
+			}
+		}
+		names = append(names, ev.name)
+	}
+	return names, packages
+}
+
+func TestE2EBatchStream(t *testing.T) {
+	app := newTestApp(t)
+
+	if w := app.get("/api/batch/stream"); w.Code != http.StatusBadRequest {
+		t.Errorf("no packages: status = %d, want 400", w.Code)
+	}
+
+	events := app.stream(t, "/api/batch/stream?packages=com.example.messenger,com.example.missing,com.example.notes")
+	names, packages := eventNames(events)
+	if strings.Join(names, ",") != "start,package,summary" || packages != 3 {
+		t.Fatalf("events = %v with %d packages, want start, 3 packages, summary", names, packages)
+	}
+
+	failed := 0
+	for _, ev := range events[1:4] {
+		var p progressEvent
+		if err := json.Unmarshal([]byte(ev.data), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Status == "failed" {
+			failed++
+			if p.Package != "com.example.missing" || p.Index != 1 {
+				t.Errorf("failed event = %+v", p)
+			}
+		} else if p.App == nil || p.App.Title == "" {
+			t.Errorf("package event without app: %s", ev.data)
+		}
+	}
+	if failed != 1 {
+		t.Errorf("%d failed package events, want 1", failed)
+	}
+
+	var sum batchSummary
+	json.Unmarshal([]byte(events[4].data), &sum)
+	if sum.Total != 3 || sum.Succeeded != 2 || sum.Failed != 1 {
+		t.Errorf("summary = %+v", sum)
+	}
+}
+
+func TestE2EJobEvents(t *testing.T) {
+	app := newTestApp(t)
+	app.runJobs(t)
+	app.store.SetLatency(20 * time.Millisecond) // still running when the stream opens
+
+	w := app.do("POST", "/api/jobs", "", `{"packages":["com.example.messenger","com.example.missing","com.example.notes"]}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("submit: %d %s", w.Code, w.Body)
+	}
+	var job jobStatus
+	json.Unmarshal(w.Body.Bytes(), &job)
+
+	// live, then replayed once finished
+	for _, pass := range []string{"live", "replay"} {
+		events := app.stream(t, "/api/jobs/"+job.ID+"/events")
+		names, packages := eventNames(events)
+		if strings.Join(names, ",") != "start,package,summary" || packages != 3 {
+			t.Fatalf("%s: events = %v with %d packages, want start, 3 packages, summary", pass, names, packages)
+		}
+
+		var sum jobStatus
+		json.Unmarshal([]byte(events[len(events)-1].data), &sum)
+		if sum.Status != "done" || sum.Succeeded != 2 || sum.Failed != 1 || sum.Results == "" {
+			t.Errorf("%s: summary = %+v", pass, sum)
+		}
+	}
+
+	if w := app.get("/api/jobs/nope/events"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: status = %d, want 404", w.Code)
+	}
+}
+
+// imageTransport answers every image request with the same PNG
+type imageTransport struct{}
+
+func (imageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := "\x89PNG\r\n\x1a\n" + req.URL.Path
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}, Request: req}, nil
+}
+
+func TestE2EAssets(t *testing.T) {
+	app := newTestApp(t)
+	var err error
+	if assetStore, err = assets.NewStore(app.db, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	assetStore.Client = &http.Client{Transport: imageTransport{}}
+	app.router = newRouter(app.db, time.Hour)
+
+	w := app.get("/api/app-info?package=com.example.notes")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var got parser.App
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if !strings.HasPrefix(got.Icon, assets.Path) || len(got.Screenshots) == 0 || !strings.HasPrefix(got.Screenshots[0], assets.Path) {
+		t.Fatalf("images not mirrored: icon %q, screenshots %q", got.Icon, got.Screenshots)
+	}
+
+	if w := app.get("/app-info?package=com.example.notes"); !strings.Contains(w.Body.String(), `src="`+got.Icon+`"`) {
+		t.Errorf("HTML page does not show the mirrored icon %s", got.Icon)
+	}
+
+	w = app.get(got.Icon)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || !strings.HasSuffix(w.Body.String(), "notes-icon") {
+		t.Errorf("GET %s = %d %q %q", got.Icon, w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+
+	// the snapshot references every mirrored image, so none is collected
+	refs, err := historyStore.Images()
+	if err != nil || !refs[got.Icon] {
+		t.Fatalf("history images = %v, %v", refs, err)
+	}
+	if res, err := assetStore.Collect(refs, 0); err != nil || res.Removed != 0 || res.Kept == 0 {
+		t.Errorf("Collect = %+v, %v", res, err)
+	}
+}
+
+func TestE2EReviews(t *testing.T) {
+	app := newTestApp(t)
+
+	w := app.get("/api/reviews/com.example.messenger?limit=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", w.Code, w.Body)
+	}
+	var body struct {
+		Reviews []parser.Review `json:"reviews"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Reviews) != 2 {
+		t.Fatalf("got %d reviews, want 2: %s", len(body.Reviews), w.Body)
+	}
+	first := body.Reviews[0]
+	if first.Author != "Jane Doe" || first.Rating != 5 || first.Helpful != 1024 || first.Reply == nil || first.Reply.Author != "Example Messenger LLC" {
+		t.Errorf("first review = %+v", first)
+	}
+	if body.Reviews[1].Rating != 2 || body.Reviews[1].Reply != nil {
+		t.Errorf("second review = %+v", body.Reviews[1])
+	}
+
+	app.get("/api/reviews/com.example.messenger")
+	if got := app.store.Requests(); got != 1 {
+		t.Errorf("upstream requests = %d, want 1 (second one cached)", got)
+	}
+	if w := app.get("/api/reviews/com.example.missing"); w.Code != http.StatusNotFound {
+		t.Errorf("missing app: status = %d, want 404", w.Code)
+	}
+}
+
+func TestE2EGraphQL(t *testing.T) {
+	app := newTestApp(t)
+
+	query := `{"query": "query($pkgs: [String!]!) { apps(packages: $pkgs) { package title installs } app(package: \"com.example.messenger\") { reviews(limit: 1) { author rating } history { installs } } }",
+		"variables": {"pkgs": ["com.example.notes", "com.example.missing"]}}`
+	w := app.do("POST", "/api/graphql", "", query)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", w.Code, w.Body)
+	}
+
+	var res struct {
+		Data struct {
+			Apps []*struct {
+				Package, Title, Installs string
+			}
+			App struct {
+				Reviews []parser.Review
+				History []json.RawMessage
+			}
+		}
+		Errors []struct{ Message string }
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data.Apps) != 2 || res.Data.Apps[0] == nil || res.Data.Apps[0].Title != "Pocket Notes" || res.Data.Apps[1] != nil {
+		t.Errorf("apps = %s", w.Body)
+	}
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "not found") {
+		t.Errorf("errors = %+v, want one for the missing app", res.Errors)
+	}
+	if len(res.Data.App.Reviews) != 1 || res.Data.App.Reviews[0].Author != "Jane Doe" {
+		t.Errorf("reviews = %+v", res.Data.App.Reviews)
+	}
+
+	// notes and missing details, messenger reviews; messenger's details page
+	// wasn't selected so it isn't fetched
+	if got := app.store.Requests(); got != 3 {
+		t.Errorf("upstream requests = %d, want 3", got)
+	}
+
+	if w := app.get("/api/graphql?query=" + url.QueryEscape(`{ app(package: "com.example.notes") { title } }`)); !strings.Contains(w.Body.String(), "Pocket Notes") {
+		t.Errorf("GET: %d %s", w.Code, w.Body)
+	}
+	if w := app.do("POST", "/api/graphql", "", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("empty query: status = %d, want 400", w.Code)
+	}
+}
+
+func TestE2ESearch(t *testing.T) {
+	app := newTestApp(t)
+
+	w := app.get("/api/search?q=Messenger")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", w.Code, w.Body)
+	}
+	var body struct {
+		Results []parser.SearchResult `json:"results"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Results) != 3 {
+		t.Fatalf("got %d results, want 3: %s", len(body.Results), w.Body)
+	}
+	want := parser.SearchResult{
+		Package:   "com.example.messenger",
+		Title:     "Example Messenger",
+		Developer: "Example Messenger LLC",
+		Icon:      "https://play-lh.googleusercontent.com/example-icon=s64",
+		Rating:    "4.3",
+	}
+	if body.Results[0] != want {
+		t.Errorf("first result = %+v, want %+v", body.Results[0], want)
+	}
+	if body.Results[2].Package != "in.example.cricket" || body.Results[2].Rating != "" {
+		t.Errorf("unrated result = %+v", body.Results[2])
+	}
+
+	app.get("/api/search?q=messenger")
+	if got := app.store.Requests(); got != 1 {
+		t.Errorf("upstream requests = %d, want 1 (second one cached)", got)
+	}
+	if w := app.get("/api/search?q=nothing+here"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"results":[]`) {
+		t.Errorf("no results: %d %s", w.Code, w.Body)
+	}
+	if w := app.get("/api/search?q=+"); w.Code != http.StatusBadRequest {
+		t.Errorf("empty query: status = %d, want 400", w.Code)
+	}
+}
+
+// grpcClient serves newGRPCServer over an in-memory listener
+func grpcClient(t *testing.T) playstorepb.PlayStoreClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := newGRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return playstorepb.NewPlayStoreClient(conn)
+}
+
+func TestE2EGRPC(t *testing.T) {
+	app := newTestApp(t)
+	client := grpcClient(t)
+	ctx := context.Background()
+
+	got, err := client.GetApp(ctx, &playstorepb.GetAppRequest{Package: "com.example.notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Pocket Notes" || got.Genre == "" || len(got.Screenshots) == 0 {
+		t.Errorf("app = %+v", got)
+	}
+	if p := got.GetPrice(); p.GetCurrency() != "USD" || p.GetAmount() != 0 || got.InAppPrice != nil {
+		t.Errorf("price = %v, in-app = %v", p, got.InAppPrice)
+	}
+
+	// served from the cache the HTTP API fills too
+	if w := app.get("/api/app-info?package=com.example.notes"); w.Code != http.StatusOK {
+		t.Fatalf("http: status = %d", w.Code)
+	}
+	if n := app.store.Requests(); n != 1 {
+		t.Errorf("upstream requests = %d, want 1", n)
+	}
+
+	if _, err := client.GetApp(ctx, &playstorepb.GetAppRequest{Package: "com.example.missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("missing app: %v, want NotFound", err)
+	}
+	if _, err := client.GetApp(ctx, &playstorepb.GetAppRequest{Package: "not a package"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("bad package: %v, want InvalidArgument", err)
+	}
+
+	stream, err := client.Batch(ctx, &playstorepb.BatchRequest{
+		Packages: []string{"com.example.messenger", "com.example.missing", "in.example.cricket", "com.example.messenger"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := make(map[int32]*playstorepb.BatchResult)
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[r.Index] = r
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3 (duplicate dropped)", len(results))
+	}
+	if r := results[0]; r.Package != "com.example.messenger" || r.App.GetTitle() != "Example Messenger" {
+		t.Errorf("result 0 = %+v", r)
+	}
+	if r := results[1]; r.App != nil || !strings.Contains(r.Error, "not found") {
+		t.Errorf("result 1 = %+v, want an error", r)
+	}
+	if r := results[2]; r.Package != "in.example.cricket" || r.App == nil {
+		t.Errorf("result 2 = %+v", r)
+	}
+
+	// capped like POST /api/batch
+	tooMany := make([]string, MaxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("com.example.app%d", i)
+	}
+	stream, err = client.Batch(ctx, &playstorepb.BatchRequest{Packages: tooMany})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("batch over MaxBatchSize: %v, want InvalidArgument", err)
+	}
+
+	search, err := client.Search(ctx, &playstorepb.SearchRequest{Query: "messenger", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(search.Results) != 2 || search.Results[1].Package != "com.example.notes" {
+		t.Errorf("search = %+v", search.Results)
+	}
+}
+
+func TestServeGRPCPortInUse(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	// returns (leaving the HTTP server up) instead of exiting the process
+	done := make(chan struct{})
+	go func() {
+		serveGRPC(lis.Addr().String())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("serveGRPC did not return on a busy port")
+	}
+}
+
+func TestE2EGRPCAPIKeys(t *testing.T) {
+	const ciKey = "ci-secret-0123456789ab"
+	newTestAppAuth(t, &testAuth{
+		limits: apikeys.Limits{RateLimit: 100, Burst: 100, DailyQuota: 1},
+		keys:   map[string]string{"ci": ciKey},
+	})
+	client := grpcClient(t)
+	req := &playstorepb.GetAppRequest{Package: "com.example.notes"}
+
+	if _, err := client.GetApp(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no key: %v, want Unauthenticated", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", ciKey)
+	if _, err := client.GetApp(ctx, req); err != nil {
+		t.Fatalf("with key: %v", err)
+	}
+	if _, err := client.GetApp(ctx, req); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("over quota: %v, want ResourceExhausted", err)
+	}
+
+	// the key's rate limit applies too
+	rateLimit, burst, quota := 0.001, 1, 0
+	_, slowKey, err := apiKeys.Create(apikeys.Spec{Name: "slow", RateLimit: &rateLimit, Burst: &burst, DailyQuota: &quota})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", slowKey)
+	if _, err := client.GetApp(ctx, req); err != nil {
+		t.Fatalf("slow key: %v", err)
+	}
+	if _, err := client.GetApp(ctx, req); status.Code(err) != codes.ResourceExhausted || !strings.Contains(err.Error(), "rate limit") {
+		t.Errorf("over rate limit: %v, want ResourceExhausted", err)
+	}
+
+	// a batch takes one request per package
+	quota = 2
+	_, smallKey, err := apiKeys.Create(apikeys.Spec{Name: "small", DailyQuota: &quota})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", smallKey)
+	stream, err := client.Batch(ctx, &playstorepb.BatchRequest{Packages: []string{"com.example.messenger", "com.example.notes", "in.example.cricket"}})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted || !strings.Contains(err.Error(), "daily quota") {
+		t.Errorf("batch over quota: %v, want ResourceExhausted", err)
+	}
+}
@@ -0,0 +1,309 @@
+// Package fakestore is a stand-in for play.google.com that serves recorded
+// pages, so the scraper and the whole app can be exercised offline. Faults
+// (latency, error codes, 429 throttling) can be injected from tests through
+// the Server methods, or over HTTP through /_fake/faults when it runs
+// standalone (cmd/fakestore).
+//
+// Pages are looked up in an fs.FS laid out as
+//
+//	details/<package>.html   GET /store/apps/details?id=<package>
+//	reviews/<package>.html   GET /store/apps/details?id=<package>&showAllReviews=true
+//	search/<query>.html      GET /store/search?q=<query>&c=apps (no-results.html otherwise)
+//	404.html                 unknown package (served with status 404)
+//
+// A flat <package>.html (as written by cmd/record-fixtures) is also accepted
+// as a details page. The bundled detail pages and 404.html are the parser's
+// own fixtures (parser/fixtures), not copies of them.
+package fakestore
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser/fixtures"
+)
+
+//go:embed pages
+var bundled embed.FS
+
+// detailFixtures maps bundled page names to the parser fixture served there
+var detailFixtures = map[string]string{
+	"details/com.example.messenger.html": "jsonld_details",
+	"details/com.example.notes.html":     "html_fallbacks",
+	"details/in.example.cricket.html":    "sibling_labels",
+	"404.html":                           "not_found",
+}
+
+// Pages returns the bundled pages: com.example.messenger (JSON-LD, reviews),
+// com.example.notes (HTML fallbacks) and in.example.cricket, plus a search
+// for "messenger"
+func Pages() fs.FS {
+	sub, _ := fs.Sub(bundled, "pages")
+	return bundledPages{sub}
+}
+
+// bundledPages serves the detail pages from the parser fixtures and
+// everything else (reviews, search) from pages/
+type bundledPages struct {
+	fs.FS
+}
+
+func (p bundledPages) Open(name string) (fs.File, error) {
+	if fixture, ok := detailFixtures[name]; ok {
+		return fixtures.FS.Open(fixture + ".html")
+	}
+	return p.FS.Open(name)
+}
+
+// Faults are the misbehaviours applied to store requests
+type Faults struct {
+	Latency time.Duration `json:"latency"` // added to every response
+
+	// FailNext answers the next FailNext requests with FailStatus (500 by default)
+	FailNext   int `json:"failNext"`
+	FailStatus int `json:"failStatus"`
+
+	// Throttle answers 429 (with Retry-After) once more than ThrottleLimit
+	// requests arrive within ThrottleWindow; 0 disables it
+	ThrottleLimit  int           `json:"throttleLimit"`
+	ThrottleWindow time.Duration `json:"throttleWindow"`
+}
+
+// Server serves store pages from an fs.FS
+type Server struct {
+	pages fs.FS
+
+	mu          sync.Mutex
+	faults      Faults
+	requests    int
+	windowStart time.Time
+	windowCount int
+}
+
+// New returns a server for pages (nil = the bundled pages)
+func New(pages fs.FS) *Server {
+	if pages == nil {
+		pages = Pages()
+	}
+	return &Server{pages: pages}
+}
+
+// Start serves s on a local httptest server; the caller must Close it
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// SetFaults replaces the injected faults (and restarts the throttle window)
+func (s *Server) SetFaults(f Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = f
+	s.windowStart, s.windowCount = time.Time{}, 0
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.Latency = d
+}
+
+// FailNext answers the next n requests with status
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.FailNext, s.faults.FailStatus = n, status
+}
+
+// Throttle answers 429 once more than limit requests arrive within window
+func (s *Server) Throttle(limit int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.ThrottleLimit, s.faults.ThrottleWindow = limit, window
+	s.windowStart, s.windowCount = time.Time{}, 0
+}
+
+// Requests returns how many store requests were received
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Reset clears faults and the request counter
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = Faults{}
+	s.requests, s.windowStart, s.windowCount = 0, time.Time{}, 0
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/_fake/faults":
+		s.serveFaults(w, r)
+		return
+	case "/store/apps/details", "/store/search":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	delay, status, wait := s.admit()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch status {
+	case 0:
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, "Too Many Requests", status)
+		return
+	default:
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	if r.URL.Path == "/store/search" {
+		s.serveSearch(w, r)
+		return
+	}
+
+	pkg := r.URL.Query().Get("id")
+	if r.URL.Query().Get("showAllReviews") == "true" {
+		s.servePage(w, "reviews/"+pkg+".html")
+		return
+	}
+	s.servePage(w, "details/"+pkg+".html", pkg+".html")
+}
+
+// admit counts a request and decides its fate: how long to delay it and
+// which error status to answer instead of the page (0 = serve it)
+func (s *Server) admit() (delay time.Duration, status int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	f := &s.faults
+
+	if f.ThrottleLimit > 0 && f.ThrottleWindow > 0 {
+		now := time.Now()
+		if now.Sub(s.windowStart) >= f.ThrottleWindow {
+			s.windowStart, s.windowCount = now, 0
+		}
+		s.windowCount++
+		if s.windowCount > f.ThrottleLimit {
+			return f.Latency, http.StatusTooManyRequests, s.windowStart.Add(f.ThrottleWindow).Sub(now)
+		}
+	}
+
+	if f.FailNext > 0 {
+		f.FailNext--
+		status := f.FailStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		return f.Latency, status, 0
+	}
+
+	return f.Latency, 0, 0
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(r.URL.Query().Get("q")), "-"), "-")
+	s.servePage(w, "search/"+q+".html", "search/no-results.html")
+}
+
+// servePage writes the first page that exists, or 404.html with status 404
+func (s *Server) servePage(w http.ResponseWriter, names ...string) {
+	for _, name := range names {
+		if !fs.ValidPath(name) || path.Base(name) == ".html" {
+			continue
+		}
+		if page, err := fs.ReadFile(s.pages, name); err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write(page)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	if page, err := fs.ReadFile(s.pages, "404.html"); err == nil {
+		w.Write(page)
+	}
+}
+
+// serveFaults handles GET (current faults and request count) and POST
+// (replace the faults) on /_fake/faults. Durations are JSON nanoseconds or
+// strings like "250ms".
+func (s *Server) serveFaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var body struct {
+			Faults
+			Latency        duration `json:"latency"`
+			ThrottleWindow duration `json:"throttleWindow"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, fmt.Sprintf("invalid faults: %v", err), http.StatusBadRequest)
+			return
+		}
+		f := body.Faults
+		f.Latency, f.ThrottleWindow = time.Duration(body.Latency), time.Duration(body.ThrottleWindow)
+		s.SetFaults(f)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	state := struct {
+		Faults   Faults `json:"faults"`
+		Requests int    `json:"requests"`
+	}{s.faults, s.requests}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// duration accepts either nanoseconds or a time.ParseDuration string
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		v, err := time.ParseDuration(s)
+		*d = duration(v)
+		return err
+	}
+	var n int64
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("duration must be a number of nanoseconds or a string like \"250ms\"")
+	}
+	*d = duration(n)
+	return nil
+}
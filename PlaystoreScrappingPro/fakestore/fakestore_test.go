@@ -0,0 +1,97 @@
+package fakestore
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerPages(t *testing.T) {
+	srv := New(nil).Start()
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/store/apps/details?id=com.example.messenger&hl=en_US", 200, "Example Messenger"},
+		{"/store/apps/details?id=com.example.messenger&showAllReviews=true", 200, `class="RHo1pe"`},
+		{"/store/apps/details?id=com.example.missing", 404, "not found"},
+		{"/store/apps/details?id=../404", 404, "not found"},
+		{"/store/search?q=Messenger&c=apps", 200, "com.example.notes"},
+		{"/store/search?q=nothing+here&c=apps", 200, "couldn't find anything"},
+		{"/elsewhere", 404, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			status, body := get(t, srv.URL+tt.path)
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if !strings.Contains(body, tt.contains) {
+				t.Errorf("body does not contain %q", tt.contains)
+			}
+		})
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	s := New(nil)
+	srv := s.Start()
+	defer srv.Close()
+	page := srv.URL + "/store/apps/details?id=com.example.notes"
+
+	s.FailNext(2, http.StatusBadGateway)
+	for i, want := range []int{502, 502, 200} {
+		if status, _ := get(t, page); status != want {
+			t.Errorf("request %d: status = %d, want %d", i+1, status, want)
+		}
+	}
+
+	s.Throttle(1, time.Minute)
+	get(t, page)
+	res, err := http.Get(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusTooManyRequests || res.Header.Get("Retry-After") == "" {
+		t.Errorf("got %d Retry-After=%q, want 429 with Retry-After", res.StatusCode, res.Header.Get("Retry-After"))
+	}
+
+	// faults can be changed over HTTP when running standalone
+	res, err = http.Post(srv.URL+"/_fake/faults", "application/json", strings.NewReader(`{"latency":"50ms"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	start := time.Now()
+	if status, _ := get(t, page); status != 200 {
+		t.Errorf("status = %d after clearing the throttle", status)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("request took %s, want at least the 50ms latency", d)
+	}
+	if n := s.Requests(); n != 6 {
+		t.Errorf("Requests() = %d, want 6", n)
+	}
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(body)
+}
@@ -0,0 +1,57 @@
+package gql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+// Request is a GraphQL request body
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Handler serves schema over
+//
+//	POST {"query": "...", "variables": {...}, "operationName": "..."}
+//	GET  ?query=...&variables={...}&operationName=...
+//
+// Errors in the query itself are answered 200 with an "errors" list, as
+// GraphQL clients expect; only malformed requests get a 400.
+func Handler(schema graphql.Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req Request
+		if c.Request.Method == http.MethodPost {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON body: " + err.Error()})
+				return
+			}
+		} else {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+			if v := c.Query("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "variables must be a JSON object"})
+					return
+				}
+			}
+		}
+		if req.Query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        c.Request.Context(),
+		})
+		c.JSON(http.StatusOK, result)
+	}
+}
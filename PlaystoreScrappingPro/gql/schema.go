@@ -0,0 +1,441 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/history"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Sources is where the schema's resolvers get their data
+type Sources struct {
+	App      func(ctx context.Context, pkg string) (*parser.App, error)
+	Reviews  func(ctx context.Context, pkg string) ([]parser.Review, error)
+	History  *history.Store
+	Validate func(pkg string) (string, error) // cleans up a package name
+	// Charge, if set, takes n more requests from the caller's quota before
+	// apps() fetches its packages (the request itself covers the first)
+	Charge func(ctx context.Context, n int) error
+
+	MaxApps     int // packages per apps() query
+	Concurrency int // pages fetched at once per query
+}
+
+// MaxReviews caps reviews(limit:)
+const MaxReviews = 200
+
+// appRef is what an App field resolves against. The detail page is only
+// fetched (once, by app/apps) when a field other than package, reviews,
+// history or changes is selected; those have their own lazy resolvers.
+type appRef struct {
+	pkg    string
+	fields map[string]interface{} // the parser.App as JSON, nil if not fetched
+}
+
+// lazyFields don't need the detail page
+var lazyFields = map[string]bool{
+	"package": true, "reviews": true, "history": true, "changes": true, "__typename": true,
+}
+
+// ratingBarType is one row of parser.Histogram
+var ratingBarType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "RatingBar",
+	Fields: graphql.Fields{
+		"stars":   &graphql.Field{Type: graphql.Int},
+		"percent": &graphql.Field{Type: graphql.Float, Description: "share of all ratings, 0 to 100"},
+		"count":   &graphql.Field{Type: graphql.Float, Description: "only when the store shows it"},
+	},
+})
+
+// priceType is parser.Price
+var priceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Price",
+	Fields: graphql.Fields{
+		"amount":   &graphql.Field{Type: graphql.Float, Description: "0 = free"},
+		"currency": &graphql.Field{Type: graphql.String, Description: "ISO 4217"},
+		"display":  &graphql.Field{Type: graphql.String, Description: "as shown on the page"},
+		"original": &graphql.Field{Type: graphql.Float, Description: "price before a running discount"},
+		"saleEnds": &graphql.Field{Type: graphql.String, Description: "RFC 3339, when that discount ends"},
+		"country":  &graphql.Field{Type: graphql.String, Description: "storefront the price is from"},
+	},
+})
+
+// priceRangeType is parser.PriceRange
+var priceRangeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PriceRange",
+	Fields: graphql.Fields{
+		"min":      &graphql.Field{Type: graphql.Float},
+		"max":      &graphql.Field{Type: graphql.Float},
+		"currency": &graphql.Field{Type: graphql.String},
+		"display":  &graphql.Field{Type: graphql.String},
+	},
+})
+
+// appFields mirrors parser.App's JSON fields
+var appFields = []struct {
+	name string // GraphQL field
+	key  string // parser.App json name
+	typ  graphql.Output
+	desc string
+}{
+	{"title", "title", graphql.String, ""},
+	{"appName", "appName", graphql.String, "store URL of the app"},
+	{"icon", "icon", graphql.String, ""},
+	{"developer", "developer", graphql.String, ""},
+	{"developerEmail", "developerEmail", graphql.String, ""},
+	{"developerWebsite", "developerWebsite", graphql.String, ""},
+	{"genre", "genre", graphql.String, ""},
+	{"rating", "rating", graphql.String, "average rating as displayed, e.g. 4.5"},
+	{"ratingCount", "ratingCount", graphql.String, "as displayed, e.g. 2.3M"},
+	{"installs", "installs", graphql.String, "as displayed, e.g. 5,000,000+"},
+	{"free", "free", graphql.Boolean, ""},
+	{"adSupported", "adSupported", graphql.Boolean, ""},
+	{"inAppPurchase", "InAppPurchase", graphql.Boolean, ""},
+	{"updated", "updated", graphql.String, ""},
+	{"version", "version", graphql.String, ""},
+	{"androidVersion", "androidVersion", graphql.String, ""},
+	{"summary", "summary", graphql.String, ""},
+	{"description", "description", graphql.String, ""},
+	{"screenshots", "screenshots", graphql.NewList(graphql.String), "phone screenshots, in carousel order"},
+	{"tabletScreenshots", "tabletScreenshots", graphql.NewList(graphql.String), ""},
+	{"featureGraphic", "featureGraphic", graphql.String, ""},
+	{"video", "video", graphql.String, "promo video (YouTube embed URL)"},
+	{"ratingHistogram", "ratingHistogram", graphql.NewList(ratingBarType), "per-star distribution, 5 stars first"},
+	{"price", "price", priceType, "null when the page shows none"},
+	{"inAppPrice", "inAppPrice", priceRangeType, "range of the in-app purchase prices"},
+}
+
+// NewSchema builds the schema:
+//
+//	app(package: String!): App
+//	apps(packages: [String!]!): [App]
+//
+// App has parser.App's fields plus reviews(limit), history(days, from, to,
+// interval) and changes(days, from, to).
+func NewSchema(src Sources) (graphql.Schema, error) {
+	if src.Concurrency <= 0 {
+		src.Concurrency = 1
+	}
+	b := &builder{src: src}
+	return graphql.NewSchema(graphql.SchemaConfig{Query: b.query()})
+}
+
+type builder struct {
+	src Sources
+}
+
+func (b *builder) query() *graphql.Object {
+	app := b.appType()
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"app": &graphql.Field{
+				Type:        app,
+				Description: "One app by package name",
+				Args: graphql.FieldConfigArgument{
+					"package": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					sem := make(chan struct{}, 1)
+					return b.resolveApp(p, p.Args["package"].(string), sem), nil
+				},
+			},
+			"apps": &graphql.Field{
+				Type:        graphql.NewList(app),
+				Description: "Several apps; one that fails is null with an error naming its index",
+				Args: graphql.FieldConfigArgument{
+					"packages": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					pkgs := p.Args["packages"].([]interface{})
+					if b.src.MaxApps > 0 && len(pkgs) > b.src.MaxApps {
+						return nil, fmt.Errorf("too many packages (max %d)", b.src.MaxApps)
+					}
+					if b.src.Charge != nil && len(pkgs) > 1 {
+						if err := b.src.Charge(p.Context, len(pkgs)-1); err != nil {
+							return nil, err
+						}
+					}
+					sem := make(chan struct{}, b.src.Concurrency)
+					out := make([]interface{}, len(pkgs))
+					for i, pkg := range pkgs {
+						out[i] = b.resolveApp(p, pkg.(string), sem)
+					}
+					return out, nil
+				},
+			},
+		},
+	})
+}
+
+// resolveApp returns the appRef for pkg, or a thunk fetching its detail page
+// in the background when the selection needs it
+func (b *builder) resolveApp(p graphql.ResolveParams, raw string, sem chan struct{}) interface{} {
+	pkg, err := b.src.Validate(raw)
+	if err != nil {
+		return func() (interface{}, error) { return nil, fmt.Errorf("%s: %v", raw, err) }
+	}
+
+	ref := &appRef{pkg: pkg}
+	if !needsDetails(p.Info) {
+		return ref
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sem <- struct{}{}
+		defer func() { <-sem }()
+
+		var app *parser.App
+		if app, err = b.src.App(p.Context, pkg); err == nil {
+			ref.fields, err = toFields(app)
+		}
+	}()
+
+	return func() (interface{}, error) {
+		<-done
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", pkg, err)
+		}
+		return ref, nil
+	}
+}
+
+func (b *builder) appType() *graphql.Object {
+	fields := graphql.Fields{
+		"package": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*appRef).pkg, nil
+			},
+		},
+	}
+
+	for _, f := range appFields {
+		key := f.key
+		fields[f.name] = &graphql.Field{
+			Type:        f.typ,
+			Description: f.desc,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*appRef).fields[key], nil
+			},
+		}
+	}
+
+	fields["reviews"] = b.reviewsField()
+	fields["history"] = b.historyField()
+	fields["changes"] = b.changesField()
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        "App",
+		Description: "A Play Store app, see parser.App",
+		Fields:      fields,
+	})
+}
+
+// rangeArgs are the date range arguments of history and changes
+var rangeArgs = graphql.FieldConfigArgument{
+	"days": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 30, Description: "range length when from is not given"},
+	"from": &graphql.ArgumentConfig{Type: graphql.String, Description: "YYYY-MM-DD or RFC 3339"},
+	"to":   &graphql.ArgumentConfig{Type: graphql.String, Description: "YYYY-MM-DD (inclusive) or RFC 3339, default now"},
+}
+
+func argRange(args map[string]interface{}) (string, string, int) {
+	from, _ := args["from"].(string)
+	to, _ := args["to"].(string)
+	days, _ := args["days"].(int)
+	return from, to, days
+}
+
+func (b *builder) historyField() *graphql.Field {
+	point := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HistoryPoint",
+		Fields: graphql.Fields{
+			"time":          &graphql.Field{Type: graphql.DateTime},
+			"samples":       &graphql.Field{Type: graphql.Int},
+			"rating":        &graphql.Field{Type: graphql.Float},
+			"ratingCount":   &graphql.Field{Type: graphql.Float},
+			"installs":      &graphql.Field{Type: graphql.String},
+			"installsCount": &graphql.Field{Type: graphql.Float},
+			"version":       &graphql.Field{Type: graphql.String},
+
+			"ratingHistogram": &graphql.Field{Type: graphql.NewList(ratingBarType)},
+		},
+	})
+	interval := graphql.NewEnum(graphql.EnumConfig{
+		Name: "Interval",
+		Values: graphql.EnumValueConfigMap{
+			"RAW":    &graphql.EnumValueConfig{Value: string(history.Raw)},
+			"DAILY":  &graphql.EnumValueConfig{Value: string(history.Daily)},
+			"WEEKLY": &graphql.EnumValueConfig{Value: string(history.Weekly)},
+		},
+	})
+
+	args := graphql.FieldConfigArgument{
+		"interval": &graphql.ArgumentConfig{Type: interval, DefaultValue: string(history.Raw)},
+	}
+	for k, v := range rangeArgs {
+		args[k] = v
+	}
+
+	return &graphql.Field{
+		Type:        graphql.NewList(point),
+		Description: "Recorded metrics, from the history store (no scraping)",
+		Args:        args,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			from, to, err := history.ParseRange(argRange(p.Args))
+			if err != nil {
+				return nil, err
+			}
+			iv, err := history.ParseInterval(p.Args["interval"].(string))
+			if err != nil {
+				return nil, err
+			}
+			return b.src.History.Series(p.Source.(*appRef).pkg, from, to, iv)
+		},
+	}
+}
+
+func (b *builder) changesField() *graphql.Field {
+	change := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Change",
+		Fields: graphql.Fields{
+			"field":   &graphql.Field{Type: graphql.String},
+			"label":   &graphql.Field{Type: graphql.String},
+			"old":     &graphql.Field{Type: graphql.String},
+			"new":     &graphql.Field{Type: graphql.String},
+			"delta":   &graphql.Field{Type: graphql.Float},
+			"added":   &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"removed": &graphql.Field{Type: graphql.NewList(graphql.String)},
+		},
+	})
+	snapshot := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Snapshot",
+		Fields: graphql.Fields{
+			"timestamp":   &graphql.Field{Type: graphql.DateTime},
+			"rating":      &graphql.Field{Type: graphql.String},
+			"ratingCount": &graphql.Field{Type: graphql.String},
+			"installs":    &graphql.Field{Type: graphql.String},
+			"version":     &graphql.Field{Type: graphql.String},
+			"changes":     &graphql.Field{Type: graphql.NewList(change)},
+
+			"ratingHistogram": &graphql.Field{Type: graphql.NewList(ratingBarType)},
+		},
+	})
+
+	return &graphql.Field{
+		Type:        graphql.NewList(snapshot),
+		Description: "Recorded snapshots that changed something",
+		Args:        rangeArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			from, to, err := history.ParseRange(argRange(p.Args))
+			if err != nil {
+				return nil, err
+			}
+			return b.src.History.Changes(p.Source.(*appRef).pkg, from, to)
+		},
+	}
+}
+
+func (b *builder) reviewsField() *graphql.Field {
+	reply := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Reply",
+		Fields: graphql.Fields{
+			"author": &graphql.Field{Type: graphql.String},
+			"date":   &graphql.Field{Type: graphql.String},
+			"text":   &graphql.Field{Type: graphql.String},
+		},
+	})
+	review := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Review",
+		Fields: graphql.Fields{
+			"author":  &graphql.Field{Type: graphql.String},
+			"rating":  &graphql.Field{Type: graphql.Int},
+			"date":    &graphql.Field{Type: graphql.String},
+			"text":    &graphql.Field{Type: graphql.String},
+			"helpful": &graphql.Field{Type: graphql.Int},
+			"reply":   &graphql.Field{Type: reply},
+		},
+	})
+
+	return &graphql.Field{
+		Type:        graphql.NewList(review),
+		Description: "Reviews shown on the store (a separate page, fetched only when selected)",
+		Args: graphql.FieldConfigArgument{
+			"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			limit := p.Args["limit"].(int)
+			if limit < 0 || limit > MaxReviews {
+				return nil, fmt.Errorf("limit must be between 0 and %d", MaxReviews)
+			}
+
+			var reviews []parser.Review
+			var err error
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				reviews, err = b.src.Reviews(p.Context, p.Source.(*appRef).pkg)
+			}()
+
+			return func() (interface{}, error) {
+				<-done
+				if err != nil {
+					return nil, err
+				}
+				return reviews[:min(limit, len(reviews))], nil
+			}, nil
+		},
+	}
+}
+
+// needsDetails reports whether the App selection has any field that comes
+// from the detail page
+func needsDetails(info graphql.ResolveInfo) bool {
+	for _, f := range info.FieldASTs {
+		if selectsDetails(f.SelectionSet, info.Fragments) {
+			return true
+		}
+	}
+	return false
+}
+
+func selectsDetails(set *ast.SelectionSet, fragments map[string]ast.Definition) bool {
+	if set == nil {
+		return false
+	}
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if !lazyFields[s.Name.Value] {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectsDetails(s.SelectionSet, fragments) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if def, ok := fragments[s.Name.Value].(*ast.FragmentDefinition); ok && selectsDetails(def.SelectionSet, fragments) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toFields turns app into its JSON object, keyed like parser.App's tags
+func toFields(app *parser.App) (map[string]interface{}, error) {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
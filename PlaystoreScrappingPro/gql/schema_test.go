@@ -0,0 +1,173 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/history"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	"github.com/graphql-go/graphql"
+)
+
+// fakeSources counts fetches per package
+type fakeSources struct {
+	mu      sync.Mutex
+	apps    map[string]int
+	reviews map[string]int
+}
+
+func (f *fakeSources) app(ctx context.Context, pkg string) (*parser.App, error) {
+	f.mu.Lock()
+	f.apps[pkg]++
+	f.mu.Unlock()
+	if pkg == "com.example.missing" {
+		return nil, errors.New("app not found on Play Store")
+	}
+	return &parser.App{
+		Title: "Title of " + pkg, Rating: "4.5", InAppPurchase: true, Screenshots: []string{"a.png"},
+		RatingHistogram: parser.Histogram{{Stars: 5, Percent: 80, Count: 8}, {Stars: 1, Percent: 20, Count: 2}},
+		Price:           &parser.Price{Amount: 1.99, Currency: "USD", Display: "$1.99", Original: 4.99, Country: "US"},
+	}, nil
+}
+
+func (f *fakeSources) reviewsOf(ctx context.Context, pkg string) ([]parser.Review, error) {
+	f.mu.Lock()
+	f.reviews[pkg]++
+	f.mu.Unlock()
+	return []parser.Review{
+		{Author: "Jane", Rating: 5, Text: "great", Reply: &parser.Reply{Author: "Dev", Text: "thanks"}},
+		{Author: "Ravi", Rating: 2, Text: "meh"},
+	}, nil
+}
+
+func newTestSchema(t *testing.T) (graphql.Schema, *fakeSources) {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "gql.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	hist, err := history.NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for i, installs := range []string{"1,000+", "5,000+", "10,000+"} {
+		app := &parser.App{Title: "x", Rating: "4.0", Installs: installs, CurrentVersion: fmt.Sprint(i), RatingHistogram: parser.Histogram{{Stars: 5, Percent: 100}}}
+		daysAgo := []int{40, 20, 1}[i]
+		if _, err := hist.Record("com.example.a", app, now.AddDate(0, 0, -daysAgo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := &fakeSources{apps: map[string]int{}, reviews: map[string]int{}}
+	schema, err := NewSchema(Sources{
+		App:     f.app,
+		Reviews: f.reviewsOf,
+		History: hist,
+		Validate: func(pkg string) (string, error) {
+			if !strings.Contains(pkg, ".") {
+				return "", errors.New("invalid package format")
+			}
+			return strings.ToLower(pkg), nil
+		},
+		MaxApps:     3,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema, f
+}
+
+func run(t *testing.T, schema graphql.Schema, query string) (string, []string) {
+	t.Helper()
+	res := graphql.Do(graphql.Params{Schema: schema, RequestString: query, Context: context.Background()})
+	data, err := json.Marshal(res.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []string
+	for _, e := range res.Errors {
+		errs = append(errs, e.Message)
+	}
+	return string(data), errs
+}
+
+func TestLazyFields(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		appFetches     int
+		reviewsFetches int
+		contains       string
+	}{
+		{"package only", `{ app(package: "com.example.a") { package } }`,
+			0, 0, `"package":"com.example.a"`},
+		{"details", `{ app(package: "com.example.a") { title rating inAppPurchase screenshots } }`,
+			1, 0, `"inAppPurchase":true`},
+		{"reviews only", `{ app(package: "com.example.a") { reviews(limit: 1) { author rating reply { text } } } }`,
+			0, 1, `"reviews":[{"author":"Jane","rating":5,"reply":{"text":"thanks"}}]`},
+		{"history only", `{ app(package: "com.example.a") { history(days: 30) { installs version } } }`,
+			0, 0, `"installs":"10,000+"`},
+		{"histogram", `{ app(package: "com.example.a") { ratingHistogram { stars percent count } } }`,
+			1, 0, `"ratingHistogram":[{"count":8,"percent":80,"stars":5},{"count":2,"percent":20,"stars":1}]`},
+		{"histogram history", `{ app(package: "com.example.a") { history(days: 30) { ratingHistogram { stars percent } } } }`,
+			0, 0, `"ratingHistogram":[{"percent":100,"stars":5}]`},
+		{"price", `{ app(package: "com.example.a") { price { amount currency original country } inAppPrice { min } } }`,
+			1, 0, `"inAppPrice":null,"price":{"amount":1.99,"country":"US","currency":"USD","original":4.99}`},
+		{"details through a fragment", `{ app(package: "com.example.a") { ...f } } fragment f on App { title }`,
+			1, 0, `"title":"Title of com.example.a"`},
+		{"everything", `{ app(package: "com.example.a") { title reviews { text } changes(days: 90) { version changes { field } } } }`,
+			1, 1, `"field":"installs"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, f := newTestSchema(t)
+			data, errs := run(t, schema, tt.query)
+			if len(errs) > 0 {
+				t.Fatalf("errors: %v", errs)
+			}
+			if !strings.Contains(data, tt.contains) {
+				t.Errorf("data %s does not contain %s", data, tt.contains)
+			}
+			if f.apps["com.example.a"] != tt.appFetches || f.reviews["com.example.a"] != tt.reviewsFetches {
+				t.Errorf("fetched app %d times and reviews %d times, want %d and %d",
+					f.apps["com.example.a"], f.reviews["com.example.a"], tt.appFetches, tt.reviewsFetches)
+			}
+		})
+	}
+}
+
+func TestApps(t *testing.T) {
+	schema, f := newTestSchema(t)
+
+	data, errs := run(t, schema, `{ apps(packages: ["com.example.a", "com.example.missing", "bad"]) { package title } }`)
+	want := `{"apps":[{"package":"com.example.a","title":"Title of com.example.a"},null,null]}`
+	if data != want {
+		t.Errorf("data = %s, want %s", data, want)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0]+errs[1], "not found") || !strings.Contains(errs[0]+errs[1], "invalid package") {
+		t.Errorf("errors = %v, want one per failed app", errs)
+	}
+	if f.apps["com.example.a"] != 1 {
+		t.Errorf("com.example.a fetched %d times, want 1", f.apps["com.example.a"])
+	}
+
+	if _, errs := run(t, schema, `{ apps(packages: ["a.a", "b.b", "c.c", "d.d"]) { package } }`); len(errs) != 1 || !strings.Contains(errs[0], "too many") {
+		t.Errorf("over MaxApps: errors = %v", errs)
+	}
+	if _, errs := run(t, schema, `{ app(package: "com.example.a") { reviews(limit: 1000) { text } } }`); len(errs) != 1 {
+		t.Errorf("reviews over MaxReviews: errors = %v", errs)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/grpcapi"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// GRPC — app, batch and search for internal consumers (GRPC_ADDR)
+///////////////////////////////////////////////////////////////////////////////
+
+// DefaultGRPCAddr is where the gRPC server listens unless GRPC_ADDR says
+// otherwise ("off" disables it). The usual gRPC port, clear of the HTTP
+// server and of cmd/webhook-receiver's :9000.
+const DefaultGRPCAddr = ":50051"
+
+// newGRPCServer serves the same data as the JSON API: fetchApp and
+// fetchSearch, with their cache, and the same API keys
+func newGRPCServer() *grpc.Server {
+	return grpcapi.NewServer(grpcapi.Sources{
+		App:             fetchApp,
+		Search:          fetchSearch,
+		ValidatePackage: sanitizePackage,
+		ValidateQuery:   searchQuery,
+		Code:            grpcCode,
+		MaxBatch:        MaxBatchSize,
+		Concurrency:     BatchConcurrency,
+	}, apiGuard)
+}
+
+// grpcCode maps a fetch error to a gRPC status code, like apiStatus
+func grpcCode(err error) codes.Code {
+	if errors.Is(err, errUpstream) {
+		return codes.Unavailable
+	}
+	return codes.NotFound
+}
+
+// serveGRPC runs the gRPC server on addr until the process exits. A bind or
+// serve error only disables gRPC: the HTTP server keeps running.
+func serveGRPC(addr string) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		slog.Error("grpc server disabled: cannot listen", "addr", addr, "error", err)
+		return
+	}
+	slog.Info("grpc server listening", "addr", lis.Addr().String())
+	if err := newGRPCServer().Serve(lis); err != nil {
+		slog.Error("grpc server stopped", "addr", addr, "error", err)
+	}
+}
@@ -0,0 +1,5 @@
+// Package playstorepb holds the messages and gRPC stubs generated from
+// playstore.proto (protoc with protoc-gen-go and protoc-gen-go-grpc on PATH).
+package playstorepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative playstore.proto
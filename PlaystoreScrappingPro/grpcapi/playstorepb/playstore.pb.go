@@ -0,0 +1,962 @@
+// gRPC API for internal consumers. It serves the same data as the JSON API
+// (same scraper, parser and cache), without the HTTP round trip per app.
+//
+// Regenerate the Go code from PlaystoreScrappingPro/ with
+//
+//	go generate ./grpcapi/playstorepb
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: playstore.proto
+
+package playstorepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetAppRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Package       string                 `protobuf:"bytes,1,opt,name=package,proto3" json:"package,omitempty"` // e.g. com.whatsapp
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAppRequest) Reset() {
+	*x = GetAppRequest{}
+	mi := &file_playstore_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAppRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAppRequest) ProtoMessage() {}
+
+func (x *GetAppRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAppRequest.ProtoReflect.Descriptor instead.
+func (*GetAppRequest) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetAppRequest) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+type BatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Packages      []string               `protobuf:"bytes,1,rep,name=packages,proto3" json:"packages,omitempty"` // duplicates are fetched once
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchRequest) Reset() {
+	*x = BatchRequest{}
+	mi := &file_playstore_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRequest) ProtoMessage() {}
+
+func (x *BatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchRequest) GetPackages() []string {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+type BatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Package       string                 `protobuf:"bytes,2,opt,name=package,proto3" json:"package,omitempty"`
+	App           *App                   `protobuf:"bytes,3,opt,name=app,proto3" json:"app,omitempty"`     // unset on error
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"` // empty on success
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_playstore_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchResult) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *BatchResult) GetApp() *App {
+	if x != nil {
+		return x.App
+	}
+	return nil
+}
+
+func (x *BatchResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // 0 = every result on the page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_playstore_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Results       []*SearchResult        `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_playstore_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchResponse) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Package       string                 `protobuf:"bytes,1,opt,name=package,proto3" json:"package,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Developer     string                 `protobuf:"bytes,3,opt,name=developer,proto3" json:"developer,omitempty"`
+	Icon          string                 `protobuf:"bytes,4,opt,name=icon,proto3" json:"icon,omitempty"`
+	Rating        string                 `protobuf:"bytes,5,opt,name=rating,proto3" json:"rating,omitempty"` // as displayed, empty if the app has none
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_playstore_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchResult) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *SearchResult) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SearchResult) GetDeveloper() string {
+	if x != nil {
+		return x.Developer
+	}
+	return ""
+}
+
+func (x *SearchResult) GetIcon() string {
+	if x != nil {
+		return x.Icon
+	}
+	return ""
+}
+
+func (x *SearchResult) GetRating() string {
+	if x != nil {
+		return x.Rating
+	}
+	return ""
+}
+
+// App mirrors the JSON API's app object. Counts and ratings are strings as
+// displayed by the store (e.g. "4.5", "2.3M", "5,000,000+").
+type App struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AppName           string                 `protobuf:"bytes,1,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"` // store URL of the app
+	Title             string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Icon              string                 `protobuf:"bytes,3,opt,name=icon,proto3" json:"icon,omitempty"`
+	Developer         string                 `protobuf:"bytes,4,opt,name=developer,proto3" json:"developer,omitempty"`
+	DeveloperEmail    string                 `protobuf:"bytes,5,opt,name=developer_email,json=developerEmail,proto3" json:"developer_email,omitempty"`
+	DeveloperWebsite  string                 `protobuf:"bytes,6,opt,name=developer_website,json=developerWebsite,proto3" json:"developer_website,omitempty"`
+	Genre             string                 `protobuf:"bytes,7,opt,name=genre,proto3" json:"genre,omitempty"`
+	Rating            string                 `protobuf:"bytes,8,opt,name=rating,proto3" json:"rating,omitempty"`
+	RatingCount       string                 `protobuf:"bytes,9,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
+	Installs          string                 `protobuf:"bytes,10,opt,name=installs,proto3" json:"installs,omitempty"`
+	Free              bool                   `protobuf:"varint,11,opt,name=free,proto3" json:"free,omitempty"`
+	AdSupported       bool                   `protobuf:"varint,12,opt,name=ad_supported,json=adSupported,proto3" json:"ad_supported,omitempty"`
+	InAppPurchase     bool                   `protobuf:"varint,13,opt,name=in_app_purchase,json=inAppPurchase,proto3" json:"in_app_purchase,omitempty"`
+	Updated           string                 `protobuf:"bytes,14,opt,name=updated,proto3" json:"updated,omitempty"`
+	Version           string                 `protobuf:"bytes,15,opt,name=version,proto3" json:"version,omitempty"`
+	AndroidVersion    string                 `protobuf:"bytes,16,opt,name=android_version,json=androidVersion,proto3" json:"android_version,omitempty"`
+	Summary           string                 `protobuf:"bytes,17,opt,name=summary,proto3" json:"summary,omitempty"`
+	Description       string                 `protobuf:"bytes,18,opt,name=description,proto3" json:"description,omitempty"`
+	Screenshots       []string               `protobuf:"bytes,19,rep,name=screenshots,proto3" json:"screenshots,omitempty"` // phone carousel, in order
+	TabletScreenshots []string               `protobuf:"bytes,20,rep,name=tablet_screenshots,json=tabletScreenshots,proto3" json:"tablet_screenshots,omitempty"`
+	FeatureGraphic    string                 `protobuf:"bytes,21,opt,name=feature_graphic,json=featureGraphic,proto3" json:"feature_graphic,omitempty"`
+	Video             string                 `protobuf:"bytes,22,opt,name=video,proto3" json:"video,omitempty"`                                            // promo video (YouTube embed URL)
+	RatingHistogram   []*RatingBar           `protobuf:"bytes,23,rep,name=rating_histogram,json=ratingHistogram,proto3" json:"rating_histogram,omitempty"` // 5 stars first
+	Price             *Price                 `protobuf:"bytes,24,opt,name=price,proto3" json:"price,omitempty"`                                            // unset when the page shows none
+	InAppPrice        *PriceRange            `protobuf:"bytes,25,opt,name=in_app_price,json=inAppPrice,proto3" json:"in_app_price,omitempty"`              // unset without in-app purchase prices
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *App) Reset() {
+	*x = App{}
+	mi := &file_playstore_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *App) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*App) ProtoMessage() {}
+
+func (x *App) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use App.ProtoReflect.Descriptor instead.
+func (*App) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *App) GetAppName() string {
+	if x != nil {
+		return x.AppName
+	}
+	return ""
+}
+
+func (x *App) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *App) GetIcon() string {
+	if x != nil {
+		return x.Icon
+	}
+	return ""
+}
+
+func (x *App) GetDeveloper() string {
+	if x != nil {
+		return x.Developer
+	}
+	return ""
+}
+
+func (x *App) GetDeveloperEmail() string {
+	if x != nil {
+		return x.DeveloperEmail
+	}
+	return ""
+}
+
+func (x *App) GetDeveloperWebsite() string {
+	if x != nil {
+		return x.DeveloperWebsite
+	}
+	return ""
+}
+
+func (x *App) GetGenre() string {
+	if x != nil {
+		return x.Genre
+	}
+	return ""
+}
+
+func (x *App) GetRating() string {
+	if x != nil {
+		return x.Rating
+	}
+	return ""
+}
+
+func (x *App) GetRatingCount() string {
+	if x != nil {
+		return x.RatingCount
+	}
+	return ""
+}
+
+func (x *App) GetInstalls() string {
+	if x != nil {
+		return x.Installs
+	}
+	return ""
+}
+
+func (x *App) GetFree() bool {
+	if x != nil {
+		return x.Free
+	}
+	return false
+}
+
+func (x *App) GetAdSupported() bool {
+	if x != nil {
+		return x.AdSupported
+	}
+	return false
+}
+
+func (x *App) GetInAppPurchase() bool {
+	if x != nil {
+		return x.InAppPurchase
+	}
+	return false
+}
+
+func (x *App) GetUpdated() string {
+	if x != nil {
+		return x.Updated
+	}
+	return ""
+}
+
+func (x *App) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *App) GetAndroidVersion() string {
+	if x != nil {
+		return x.AndroidVersion
+	}
+	return ""
+}
+
+func (x *App) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *App) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *App) GetScreenshots() []string {
+	if x != nil {
+		return x.Screenshots
+	}
+	return nil
+}
+
+func (x *App) GetTabletScreenshots() []string {
+	if x != nil {
+		return x.TabletScreenshots
+	}
+	return nil
+}
+
+func (x *App) GetFeatureGraphic() string {
+	if x != nil {
+		return x.FeatureGraphic
+	}
+	return ""
+}
+
+func (x *App) GetVideo() string {
+	if x != nil {
+		return x.Video
+	}
+	return ""
+}
+
+func (x *App) GetRatingHistogram() []*RatingBar {
+	if x != nil {
+		return x.RatingHistogram
+	}
+	return nil
+}
+
+func (x *App) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *App) GetInAppPrice() *PriceRange {
+	if x != nil {
+		return x.InAppPrice
+	}
+	return nil
+}
+
+// RatingBar is one row of the star rating histogram
+type RatingBar struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stars         int32                  `protobuf:"varint,1,opt,name=stars,proto3" json:"stars,omitempty"`
+	Percent       float64                `protobuf:"fixed64,2,opt,name=percent,proto3" json:"percent,omitempty"` // share of all ratings, 0 to 100
+	Count         int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`      // 0 when the store doesn't show it
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RatingBar) Reset() {
+	*x = RatingBar{}
+	mi := &file_playstore_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RatingBar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RatingBar) ProtoMessage() {}
+
+func (x *RatingBar) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RatingBar.ProtoReflect.Descriptor instead.
+func (*RatingBar) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RatingBar) GetStars() int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *RatingBar) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *RatingBar) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// Price is what the app costs on the storefront of country
+type Price struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        float64                `protobuf:"fixed64,1,opt,name=amount,proto3" json:"amount,omitempty"`                   // 0 = free
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`                 // ISO 4217
+	Display       string                 `protobuf:"bytes,3,opt,name=display,proto3" json:"display,omitempty"`                   // as shown, e.g. ₹49.00
+	Original      float64                `protobuf:"fixed64,4,opt,name=original,proto3" json:"original,omitempty"`               // price before a running discount, 0 when none
+	SaleEnds      string                 `protobuf:"bytes,5,opt,name=sale_ends,json=saleEnds,proto3" json:"sale_ends,omitempty"` // RFC 3339, empty when not shown
+	Country       string                 `protobuf:"bytes,6,opt,name=country,proto3" json:"country,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Price) Reset() {
+	*x = Price{}
+	mi := &file_playstore_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Price) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Price) ProtoMessage() {}
+
+func (x *Price) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Price.ProtoReflect.Descriptor instead.
+func (*Price) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Price) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Price) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Price) GetDisplay() string {
+	if x != nil {
+		return x.Display
+	}
+	return ""
+}
+
+func (x *Price) GetOriginal() float64 {
+	if x != nil {
+		return x.Original
+	}
+	return 0
+}
+
+func (x *Price) GetSaleEnds() string {
+	if x != nil {
+		return x.SaleEnds
+	}
+	return ""
+}
+
+func (x *Price) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+// PriceRange spans the app's in-app purchase prices
+type PriceRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           float64                `protobuf:"fixed64,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,2,opt,name=max,proto3" json:"max,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Display       string                 `protobuf:"bytes,4,opt,name=display,proto3" json:"display,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceRange) Reset() {
+	*x = PriceRange{}
+	mi := &file_playstore_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceRange) ProtoMessage() {}
+
+func (x *PriceRange) ProtoReflect() protoreflect.Message {
+	mi := &file_playstore_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceRange.ProtoReflect.Descriptor instead.
+func (*PriceRange) Descriptor() ([]byte, []int) {
+	return file_playstore_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PriceRange) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *PriceRange) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *PriceRange) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PriceRange) GetDisplay() string {
+	if x != nil {
+		return x.Display
+	}
+	return ""
+}
+
+var File_playstore_proto protoreflect.FileDescriptor
+
+const file_playstore_proto_rawDesc = "" +
+	"\n" +
+	"\x0fplaystore.proto\x12\fplaystore.v1\")\n" +
+	"\rGetAppRequest\x12\x18\n" +
+	"\apackage\x18\x01 \x01(\tR\apackage\"*\n" +
+	"\fBatchRequest\x12\x1a\n" +
+	"\bpackages\x18\x01 \x03(\tR\bpackages\"x\n" +
+	"\vBatchResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x18\n" +
+	"\apackage\x18\x02 \x01(\tR\apackage\x12#\n" +
+	"\x03app\x18\x03 \x01(\v2\x11.playstore.v1.AppR\x03app\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\";\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\\\n" +
+	"\x0eSearchResponse\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x124\n" +
+	"\aresults\x18\x02 \x03(\v2\x1a.playstore.v1.SearchResultR\aresults\"\x88\x01\n" +
+	"\fSearchResult\x12\x18\n" +
+	"\apackage\x18\x01 \x01(\tR\apackage\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x1c\n" +
+	"\tdeveloper\x18\x03 \x01(\tR\tdeveloper\x12\x12\n" +
+	"\x04icon\x18\x04 \x01(\tR\x04icon\x12\x16\n" +
+	"\x06rating\x18\x05 \x01(\tR\x06rating\"\xde\x06\n" +
+	"\x03App\x12\x19\n" +
+	"\bapp_name\x18\x01 \x01(\tR\aappName\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04icon\x18\x03 \x01(\tR\x04icon\x12\x1c\n" +
+	"\tdeveloper\x18\x04 \x01(\tR\tdeveloper\x12'\n" +
+	"\x0fdeveloper_email\x18\x05 \x01(\tR\x0edeveloperEmail\x12+\n" +
+	"\x11developer_website\x18\x06 \x01(\tR\x10developerWebsite\x12\x14\n" +
+	"\x05genre\x18\a \x01(\tR\x05genre\x12\x16\n" +
+	"\x06rating\x18\b \x01(\tR\x06rating\x12!\n" +
+	"\frating_count\x18\t \x01(\tR\vratingCount\x12\x1a\n" +
+	"\binstalls\x18\n" +
+	" \x01(\tR\binstalls\x12\x12\n" +
+	"\x04free\x18\v \x01(\bR\x04free\x12!\n" +
+	"\fad_supported\x18\f \x01(\bR\vadSupported\x12&\n" +
+	"\x0fin_app_purchase\x18\r \x01(\bR\rinAppPurchase\x12\x18\n" +
+	"\aupdated\x18\x0e \x01(\tR\aupdated\x12\x18\n" +
+	"\aversion\x18\x0f \x01(\tR\aversion\x12'\n" +
+	"\x0fandroid_version\x18\x10 \x01(\tR\x0eandroidVersion\x12\x18\n" +
+	"\asummary\x18\x11 \x01(\tR\asummary\x12 \n" +
+	"\vdescription\x18\x12 \x01(\tR\vdescription\x12 \n" +
+	"\vscreenshots\x18\x13 \x03(\tR\vscreenshots\x12-\n" +
+	"\x12tablet_screenshots\x18\x14 \x03(\tR\x11tabletScreenshots\x12'\n" +
+	"\x0ffeature_graphic\x18\x15 \x01(\tR\x0efeatureGraphic\x12\x14\n" +
+	"\x05video\x18\x16 \x01(\tR\x05video\x12B\n" +
+	"\x10rating_histogram\x18\x17 \x03(\v2\x17.playstore.v1.RatingBarR\x0fratingHistogram\x12)\n" +
+	"\x05price\x18\x18 \x01(\v2\x13.playstore.v1.PriceR\x05price\x12:\n" +
+	"\fin_app_price\x18\x19 \x01(\v2\x18.playstore.v1.PriceRangeR\n" +
+	"inAppPrice\"Q\n" +
+	"\tRatingBar\x12\x14\n" +
+	"\x05stars\x18\x01 \x01(\x05R\x05stars\x12\x18\n" +
+	"\apercent\x18\x02 \x01(\x01R\apercent\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\"\xa8\x01\n" +
+	"\x05Price\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12\x18\n" +
+	"\adisplay\x18\x03 \x01(\tR\adisplay\x12\x1a\n" +
+	"\boriginal\x18\x04 \x01(\x01R\boriginal\x12\x1b\n" +
+	"\tsale_ends\x18\x05 \x01(\tR\bsaleEnds\x12\x18\n" +
+	"\acountry\x18\x06 \x01(\tR\acountry\"f\n" +
+	"\n" +
+	"PriceRange\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\x02 \x01(\x01R\x03max\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x18\n" +
+	"\adisplay\x18\x04 \x01(\tR\adisplay2\xcc\x01\n" +
+	"\tPlayStore\x128\n" +
+	"\x06GetApp\x12\x1b.playstore.v1.GetAppRequest\x1a\x11.playstore.v1.App\x12@\n" +
+	"\x05Batch\x12\x1a.playstore.v1.BatchRequest\x1a\x19.playstore.v1.BatchResult0\x01\x12C\n" +
+	"\x06Search\x12\x1b.playstore.v1.SearchRequest\x1a\x1c.playstore.v1.SearchResponseBgZegithub.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/grpcapi/playstorepbb\x06proto3"
+
+var (
+	file_playstore_proto_rawDescOnce sync.Once
+	file_playstore_proto_rawDescData []byte
+)
+
+func file_playstore_proto_rawDescGZIP() []byte {
+	file_playstore_proto_rawDescOnce.Do(func() {
+		file_playstore_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_playstore_proto_rawDesc), len(file_playstore_proto_rawDesc)))
+	})
+	return file_playstore_proto_rawDescData
+}
+
+var file_playstore_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_playstore_proto_goTypes = []any{
+	(*GetAppRequest)(nil),  // 0: playstore.v1.GetAppRequest
+	(*BatchRequest)(nil),   // 1: playstore.v1.BatchRequest
+	(*BatchResult)(nil),    // 2: playstore.v1.BatchResult
+	(*SearchRequest)(nil),  // 3: playstore.v1.SearchRequest
+	(*SearchResponse)(nil), // 4: playstore.v1.SearchResponse
+	(*SearchResult)(nil),   // 5: playstore.v1.SearchResult
+	(*App)(nil),            // 6: playstore.v1.App
+	(*RatingBar)(nil),      // 7: playstore.v1.RatingBar
+	(*Price)(nil),          // 8: playstore.v1.Price
+	(*PriceRange)(nil),     // 9: playstore.v1.PriceRange
+}
+var file_playstore_proto_depIdxs = []int32{
+	6, // 0: playstore.v1.BatchResult.app:type_name -> playstore.v1.App
+	5, // 1: playstore.v1.SearchResponse.results:type_name -> playstore.v1.SearchResult
+	7, // 2: playstore.v1.App.rating_histogram:type_name -> playstore.v1.RatingBar
+	8, // 3: playstore.v1.App.price:type_name -> playstore.v1.Price
+	9, // 4: playstore.v1.App.in_app_price:type_name -> playstore.v1.PriceRange
+	0, // 5: playstore.v1.PlayStore.GetApp:input_type -> playstore.v1.GetAppRequest
+	1, // 6: playstore.v1.PlayStore.Batch:input_type -> playstore.v1.BatchRequest
+	3, // 7: playstore.v1.PlayStore.Search:input_type -> playstore.v1.SearchRequest
+	6, // 8: playstore.v1.PlayStore.GetApp:output_type -> playstore.v1.App
+	2, // 9: playstore.v1.PlayStore.Batch:output_type -> playstore.v1.BatchResult
+	4, // 10: playstore.v1.PlayStore.Search:output_type -> playstore.v1.SearchResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_playstore_proto_init() }
+func file_playstore_proto_init() {
+	if File_playstore_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_playstore_proto_rawDesc), len(file_playstore_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_playstore_proto_goTypes,
+		DependencyIndexes: file_playstore_proto_depIdxs,
+		MessageInfos:      file_playstore_proto_msgTypes,
+	}.Build()
+	File_playstore_proto = out.File
+	file_playstore_proto_goTypes = nil
+	file_playstore_proto_depIdxs = nil
+}
@@ -0,0 +1,220 @@
+// gRPC API for internal consumers. It serves the same data as the JSON API
+// (same scraper, parser and cache), without the HTTP round trip per app.
+//
+// Regenerate the Go code from PlaystoreScrappingPro/ with
+//
+//	go generate ./grpcapi/playstorepb
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: playstore.proto
+
+package playstorepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PlayStore_GetApp_FullMethodName = "/playstore.v1.PlayStore/GetApp"
+	PlayStore_Batch_FullMethodName  = "/playstore.v1.PlayStore/Batch"
+	PlayStore_Search_FullMethodName = "/playstore.v1.PlayStore/Search"
+)
+
+// PlayStoreClient is the client API for PlayStore service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlayStoreClient interface {
+	// GetApp returns one app's details (cache first). Errors: INVALID_ARGUMENT
+	// for a bad package name, NOT_FOUND, UNAVAILABLE when Google Play can't be
+	// reached.
+	GetApp(ctx context.Context, in *GetAppRequest, opts ...grpc.CallOption) (*App, error)
+	// Batch streams one result per package as soon as it is known, in
+	// completion order; index is the package's position in the request.
+	Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BatchResult], error)
+	// Search returns the apps Google Play lists for a query.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+}
+
+type playStoreClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlayStoreClient(cc grpc.ClientConnInterface) PlayStoreClient {
+	return &playStoreClient{cc}
+}
+
+func (c *playStoreClient) GetApp(ctx context.Context, in *GetAppRequest, opts ...grpc.CallOption) (*App, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(App)
+	err := c.cc.Invoke(ctx, PlayStore_GetApp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playStoreClient) Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BatchResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PlayStore_ServiceDesc.Streams[0], PlayStore_Batch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchRequest, BatchResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PlayStore_BatchClient = grpc.ServerStreamingClient[BatchResult]
+
+func (c *playStoreClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, PlayStore_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PlayStoreServer is the server API for PlayStore service.
+// All implementations must embed UnimplementedPlayStoreServer
+// for forward compatibility.
+type PlayStoreServer interface {
+	// GetApp returns one app's details (cache first). Errors: INVALID_ARGUMENT
+	// for a bad package name, NOT_FOUND, UNAVAILABLE when Google Play can't be
+	// reached.
+	GetApp(context.Context, *GetAppRequest) (*App, error)
+	// Batch streams one result per package as soon as it is known, in
+	// completion order; index is the package's position in the request.
+	Batch(*BatchRequest, grpc.ServerStreamingServer[BatchResult]) error
+	// Search returns the apps Google Play lists for a query.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	mustEmbedUnimplementedPlayStoreServer()
+}
+
+// UnimplementedPlayStoreServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPlayStoreServer struct{}
+
+func (UnimplementedPlayStoreServer) GetApp(context.Context, *GetAppRequest) (*App, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApp not implemented")
+}
+func (UnimplementedPlayStoreServer) Batch(*BatchRequest, grpc.ServerStreamingServer[BatchResult]) error {
+	return status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedPlayStoreServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedPlayStoreServer) mustEmbedUnimplementedPlayStoreServer() {}
+func (UnimplementedPlayStoreServer) testEmbeddedByValue()                   {}
+
+// UnsafePlayStoreServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlayStoreServer will
+// result in compilation errors.
+type UnsafePlayStoreServer interface {
+	mustEmbedUnimplementedPlayStoreServer()
+}
+
+func RegisterPlayStoreServer(s grpc.ServiceRegistrar, srv PlayStoreServer) {
+	// If the following call pancis, it indicates UnimplementedPlayStoreServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PlayStore_ServiceDesc, srv)
+}
+
+func _PlayStore_GetApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayStoreServer).GetApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlayStore_GetApp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayStoreServer).GetApp(ctx, req.(*GetAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlayStore_Batch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlayStoreServer).Batch(m, &grpc.GenericServerStream[BatchRequest, BatchResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PlayStore_BatchServer = grpc.ServerStreamingServer[BatchResult]
+
+func _PlayStore_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayStoreServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlayStore_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayStoreServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PlayStore_ServiceDesc is the grpc.ServiceDesc for PlayStore service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlayStore_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "playstore.v1.PlayStore",
+	HandlerType: (*PlayStoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetApp",
+			Handler:    _PlayStore_GetApp_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _PlayStore_Search_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Batch",
+			Handler:       _PlayStore_Batch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "playstore.proto",
+}
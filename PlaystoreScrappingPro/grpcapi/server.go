@@ -0,0 +1,349 @@
+// Package grpcapi serves the PlayStore gRPC service (see
+// playstorepb/playstore.proto) for internal consumers. It runs next to the
+// Gin server and gets its data from the same functions, so both share the
+// scraper, parser and cache.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/apikeys"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/grpcapi/playstorepb"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Sources is where the service gets its data
+type Sources struct {
+	App    func(ctx context.Context, pkg string) (*parser.App, error)
+	Search func(ctx context.Context, query string) ([]parser.SearchResult, error)
+
+	ValidatePackage func(pkg string) (string, error)   // cleans up a package name
+	ValidateQuery   func(query string) (string, error) // cleans up a search query
+
+	// Code maps an App or Search error to a status code (nil = UNAVAILABLE)
+	Code func(err error) codes.Code
+
+	MaxBatch    int // packages per Batch call
+	Concurrency int // pages fetched at once per Batch call
+}
+
+// server implements playstorepb.PlayStoreServer
+type server struct {
+	playstorepb.UnimplementedPlayStoreServer
+	src   Sources
+	guard *apikeys.Guard // nil = no key required
+}
+
+// NewServer returns a gRPC server with the PlayStore service registered.
+// When guard is set every call must carry a valid key in the x-api-key (or
+// "authorization: Bearer ...") metadata; calls are held to the key's rate
+// limit and daily quota like HTTP requests are.
+func NewServer(src Sources, guard *apikeys.Guard, opts ...grpc.ServerOption) *grpc.Server {
+	s := &server{src: src, guard: guard}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	g := grpc.NewServer(opts...)
+	playstorepb.RegisterPlayStoreServer(g, s)
+	return g
+}
+
+func (s *server) GetApp(ctx context.Context, req *playstorepb.GetAppRequest) (*playstorepb.App, error) {
+	pkg, err := s.src.ValidatePackage(req.GetPackage())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	app, err := s.src.App(ctx, pkg)
+	if err != nil {
+		return nil, s.status(err)
+	}
+	return toApp(app), nil
+}
+
+func (s *server) Batch(req *playstorepb.BatchRequest, stream grpc.ServerStreamingServer[playstorepb.BatchResult]) error {
+	seen := make(map[string]bool)
+	var pkgs []string
+	for _, raw := range req.GetPackages() {
+		pkg, err := s.src.ValidatePackage(raw)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s: %v", strings.TrimSpace(raw), err)
+		}
+		if !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if len(pkgs) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one package name is required")
+	}
+	if s.src.MaxBatch > 0 && len(pkgs) > s.src.MaxBatch {
+		return status.Errorf(codes.InvalidArgument, "too many packages (max %d)", s.src.MaxBatch)
+	}
+	// the call itself covered the first package
+	if rej := s.guard.Charge(stream.Context(), len(pkgs)-1); rej != nil {
+		return status.Errorf(codes.ResourceExhausted, "%s (retry in %ds)", rej.Message, rej.RetrySeconds())
+	}
+
+	// a failed Send cancels the fetches still running
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	results := make(chan *playstorepb.BatchResult)
+	go func() {
+		defer close(results)
+		s.fetchEach(ctx, pkgs, results)
+	}()
+
+	for r := range results {
+		if err := stream.Send(r); err != nil {
+			cancel()
+			for range results {
+			}
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// fetchEach fetches pkgs with bounded concurrency, sending each result to
+// results as soon as it is known, until done or ctx is cancelled
+func (s *server) fetchEach(ctx context.Context, pkgs []string, results chan<- *playstorepb.BatchResult) {
+	sem := make(chan struct{}, max(s.src.Concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, pkg := range pkgs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, pkg string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			r := &playstorepb.BatchResult{Index: int32(i), Package: pkg}
+			app, err := s.src.App(ctx, pkg)
+			if err != nil {
+				r.Error = err.Error()
+			} else {
+				r.App = toApp(app)
+			}
+			select {
+			case results <- r:
+			case <-ctx.Done():
+			}
+		}(i, pkg)
+	}
+
+	wg.Wait()
+}
+
+func (s *server) Search(ctx context.Context, req *playstorepb.SearchRequest) (*playstorepb.SearchResponse, error) {
+	q, err := s.src.ValidateQuery(req.GetQuery())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	found, err := s.src.Search(ctx, q)
+	if err != nil {
+		return nil, s.status(err)
+	}
+	if limit := int(req.GetLimit()); limit > 0 && limit < len(found) {
+		found = found[:limit]
+	}
+
+	resp := &playstorepb.SearchResponse{Query: q, Results: make([]*playstorepb.SearchResult, len(found))}
+	for i, r := range found {
+		resp.Results[i] = &playstorepb.SearchResult{
+			Package:   r.Package,
+			Title:     r.Title,
+			Developer: r.Developer,
+			Icon:      r.Icon,
+			Rating:    r.Rating,
+		}
+	}
+	return resp, nil
+}
+
+// status turns a data source error into a gRPC status
+func (s *server) status(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case s.src.Code != nil:
+		return status.Error(s.src.Code(err), err.Error())
+	default:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
+
+func toApp(app *parser.App) *playstorepb.App {
+	return &playstorepb.App{
+		AppName:           app.AppName,
+		Title:             app.Title,
+		Icon:              app.Icon,
+		Developer:         app.Developer,
+		DeveloperEmail:    app.DeveloperEmail,
+		DeveloperWebsite:  app.DeveloperWebsite,
+		Genre:             app.Category,
+		Rating:            app.Rating,
+		RatingCount:       app.RatingCount,
+		Installs:          app.Installs,
+		Free:              app.Free,
+		AdSupported:       app.AdSupported,
+		InAppPurchase:     app.InAppPurchase,
+		Updated:           app.LastUpdated,
+		Version:           app.CurrentVersion,
+		AndroidVersion:    app.AndroidVersion,
+		Summary:           app.ShortDesc,
+		Description:       app.Description,
+		Screenshots:       app.Screenshots,
+		TabletScreenshots: app.TabletScreenshots,
+		FeatureGraphic:    app.FeatureGraphic,
+		Video:             app.Video,
+		RatingHistogram:   toHistogram(app.RatingHistogram),
+		Price:             toPrice(app.Price),
+		InAppPrice:        toPriceRange(app.InAppPrice),
+	}
+}
+
+func toPrice(p *parser.Price) *playstorepb.Price {
+	if p == nil {
+		return nil
+	}
+	out := &playstorepb.Price{Amount: p.Amount, Currency: p.Currency, Display: p.Display, Original: p.Original, Country: p.Country}
+	if p.SaleEnds != nil {
+		out.SaleEnds = p.SaleEnds.Format(time.RFC3339)
+	}
+	return out
+}
+
+func toPriceRange(r *parser.PriceRange) *playstorepb.PriceRange {
+	if r == nil {
+		return nil
+	}
+	return &playstorepb.PriceRange{Min: r.Min, Max: r.Max, Currency: r.Currency, Display: r.Display}
+}
+
+func toHistogram(h parser.Histogram) []*playstorepb.RatingBar {
+	out := make([]*playstorepb.RatingBar, len(h))
+	for i, b := range h {
+		out[i] = &playstorepb.RatingBar{Stars: int32(b.Stars), Percent: b.Percent, Count: b.Count}
+	}
+	return out
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// INTERCEPTORS — request IDs, API keys and logging
+///////////////////////////////////////////////////////////////////////////////
+
+func (s *server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx, err := s.admit(ctx)
+	var resp any
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func (s *server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, err := s.admit(ss.Context())
+	if err == nil {
+		err = handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+	logCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// contextStream is a ServerStream with the admitted call's context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+// admit tags ctx with a request ID (reusing a sane x-request-id from the
+// caller) and checks the caller's API key
+func (s *server) admit(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := first(md, strings.ToLower(logging.RequestIDHeader))
+	if !logging.ValidID(id) {
+		id = logging.NewID()
+	}
+	ctx = logging.WithRequestID(ctx, id)
+
+	if s.guard == nil {
+		return ctx, nil
+	}
+
+	secret := first(md, strings.ToLower(apikeys.Header))
+	if secret == "" {
+		secret, _ = strings.CutPrefix(first(md, "authorization"), "Bearer ")
+	}
+	if secret = strings.TrimSpace(secret); secret == "" {
+		return ctx, status.Error(codes.Unauthenticated, "missing API key (send it in the "+strings.ToLower(apikeys.Header)+" metadata)")
+	}
+	key := s.guard.Lookup(secret)
+	if key == nil || key.RevokedAt != nil {
+		return ctx, status.Error(codes.Unauthenticated, "invalid or revoked API key")
+	}
+
+	if _, rej := s.guard.Admit(key); rej != nil {
+		return ctx, status.Errorf(codes.ResourceExhausted, "%s (retry in %ds)", rej.Message, rej.RetrySeconds())
+	}
+	return apikeys.WithKey(ctx, key), nil
+}
+
+// first returns the first value of a metadata key ("" if absent)
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// logCall logs a finished call like middleware.RequestLog logs HTTP requests
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.Unavailable:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+	logging.From(ctx).Log(ctx, level, "grpc call",
+		"method", method,
+		"code", code.String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+}
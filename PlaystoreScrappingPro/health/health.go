@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout bounds every readiness check so a hung dependency can't hang the probe
+const Timeout = 2 * time.Second
+
+// Check reports whether one dependency is usable (nil = ready)
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one named check
+type Result struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+// Checker runs a fixed, ordered set of named readiness checks
+type Checker struct {
+	names  []string
+	checks map[string]Check
+}
+
+// NewChecker returns an empty checker
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add registers a check under name (replacing any earlier one)
+func (c *Checker) Add(name string, check Check) {
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// Run executes every check concurrently and reports whether all passed
+func (c *Checker) Run(ctx context.Context) ([]Result, bool) {
+	results := make([]Result, len(c.names))
+	done := make(chan struct{}, len(c.names))
+
+	for i, name := range c.names {
+		go func(i int, name string) {
+			results[i] = run(ctx, name, c.checks[name])
+			done <- struct{}{}
+		}(i, name)
+	}
+	for range c.names {
+		<-done
+	}
+
+	ok := true
+	for _, r := range results {
+		ok = ok && r.OK
+	}
+	return results, ok
+}
+
+func run(ctx context.Context, name string, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				errc <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		errc <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", Timeout)
+	}
+
+	res := Result{Name: name, OK: err == nil, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Liveness handles GET /healthz: the process is up and serving requests
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness returns a GET /readyz handler, 503 while any check fails
+func (c *Checker) Readiness() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		results, ok := c.Run(ctx.Request.Context())
+
+		status, code := "ok", http.StatusOK
+		if !ok {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+		ctx.JSON(code, gin.H{"status": status, "checks": results})
+	}
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRunConcurrentTimeout(t *testing.T) {
+	c := NewChecker()
+	// hang ignores ctx, like a dependency stuck in a call without a deadline
+	stuck := make(chan struct{})
+	defer close(stuck)
+	hang := func(ctx context.Context) error { <-stuck; return nil }
+	c.Add("a", hang)
+	c.Add("b", hang)
+	c.Add("ok", func(ctx context.Context) error { return nil })
+
+	// the checks run at once, so both hung ones give up at the same deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results, ok := c.Run(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Run took %s, want the checks run concurrently", elapsed)
+	}
+
+	if ok {
+		t.Error("Run reports ok with hung checks")
+	}
+	want := []struct {
+		name string
+		ok   bool
+	}{{"a", false}, {"b", false}, {"ok", true}}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Name != w.name || r.OK != w.ok {
+			t.Errorf("result %d = %+v, want %s ok=%v", i, r, w.name, w.ok)
+		}
+		if !r.OK && !strings.Contains(r.Error, "timed out") {
+			t.Errorf("%s: error %q, want a timeout", r.Name, r.Error)
+		}
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		checks map[string]Check
+		code   int
+		status string
+		errors map[string]string
+	}{
+		{
+			name:   "all ready",
+			checks: map[string]Check{"db": func(ctx context.Context) error { return nil }},
+			code:   http.StatusOK, status: "ok",
+		},
+		{
+			name: "one failing",
+			checks: map[string]Check{
+				"db":    func(ctx context.Context) error { return nil },
+				"cache": func(ctx context.Context) error { return errors.New("cache down") },
+			},
+			code: http.StatusServiceUnavailable, status: "unavailable",
+			errors: map[string]string{"cache": "cache down"},
+		},
+		{
+			name:   "panic",
+			checks: map[string]Check{"db": func(ctx context.Context) error { panic("boom") }},
+			code:   http.StatusServiceUnavailable, status: "unavailable",
+			errors: map[string]string{"db": "panic: boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker()
+			for name, check := range tt.checks {
+				c.Add(name, check)
+			}
+			r := gin.New()
+			r.GET("/readyz", c.Readiness())
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			var body struct {
+				Status string   `json:"status"`
+				Checks []Result `json:"checks"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.code || body.Status != tt.status || len(body.Checks) != len(tt.checks) {
+				t.Fatalf("%d %s", w.Code, w.Body)
+			}
+			for _, res := range body.Checks {
+				if want := tt.errors[res.Name]; res.Error != want || res.OK != (want == "") {
+					t.Errorf("%s: ok=%v error=%q, want error %q", res.Name, res.OK, res.Error, want)
+				}
+			}
+		})
+	}
+}
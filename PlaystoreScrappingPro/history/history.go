@@ -0,0 +1,394 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/diff"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Layout: bucket "history" -> one sub-bucket per package -> key = big-endian
+// UnixNano timestamp, value = JSON Snapshot. Keys sort chronologically so a
+// cursor Seek gives date-range queries for free (which only holds for
+// timestamps from 1970 to 2262, see timeKey). Bucket "history-latest"
+// keeps the last full parser.App per package so the next snapshot can be
+// diffed against it. Bucket "history-images" indexes every image URL any
+// snapshot recorded (key = URL, empty value), so Images doesn't have to read
+// the whole history.
+const (
+	bucketName   = "history"
+	latestBucket = "history-latest"
+	imagesBucket = "history-images"
+)
+
+// Snapshot is the metrics recorded for every successful parse of an app,
+// plus the field-by-field changes since the previous parse
+type Snapshot struct {
+	Timestamp   time.Time     `json:"timestamp"`
+	Rating      string        `json:"rating"`
+	RatingCount string        `json:"ratingCount"`
+	Installs    string        `json:"installs"`
+	Version     string        `json:"version"`
+	Changes     []diff.Change `json:"changes,omitempty"`
+	Images      []string      `json:"images,omitempty"` // every image URL of the app, as served
+
+	RatingHistogram parser.Histogram `json:"ratingHistogram,omitempty"`
+}
+
+// Store records and queries snapshots
+type Store struct {
+	db *storage.DB
+}
+
+// NewStore prepares the history buckets in db, building the image index
+// from the recorded history the first time
+func NewStore(db *storage.DB) (*Store, error) {
+	if err := db.EnsureBuckets(bucketName, latestBucket); err != nil {
+		return nil, err
+	}
+	err := db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(imagesBucket)) != nil {
+			return nil
+		}
+		index, err := tx.CreateBucket([]byte(imagesBucket))
+		if err != nil {
+			return err
+		}
+		return backfillImages(tx, index)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Record appends a snapshot of app for pkg taken at time at, diffed against
+// the previously recorded app, and returns it
+func (s *Store) Record(pkg string, app *parser.App, at time.Time) (Snapshot, error) {
+	if at.Before(minKeyTime) || at.After(maxKeyTime) {
+		return Snapshot{}, fmt.Errorf("timestamp %s is outside the recordable range (1970 to 2262)", at.UTC().Format(time.RFC3339))
+	}
+
+	snap := Snapshot{
+		Timestamp:   at.UTC(),
+		Rating:      app.Rating,
+		RatingCount: app.RatingCount,
+		Installs:    app.Installs,
+		Version:     app.CurrentVersion,
+		Images:      app.Images(),
+
+		RatingHistogram: app.RatingHistogram,
+	}
+
+	current, err := json.Marshal(app)
+	if err != nil {
+		return snap, err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		latest := tx.Bucket([]byte(latestBucket))
+
+		if prev := latest.Get([]byte(pkg)); prev != nil {
+			var old parser.App
+			if err := json.Unmarshal(prev, &old); err == nil {
+				snap.Changes = diff.Compare(&old, app)
+			}
+		}
+
+		data, err := json.Marshal(snap)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket([]byte(bucketName)).CreateBucketIfNotExists([]byte(pkg))
+		if err != nil {
+			return err
+		}
+		if err := b.Put(timeKey(snap.Timestamp), data); err != nil {
+			return err
+		}
+		if err := indexImages(tx.Bucket([]byte(imagesBucket)), snap.Images); err != nil {
+			return err
+		}
+		return latest.Put([]byte(pkg), current)
+	})
+
+	return snap, err
+}
+
+// Latest returns the last recorded app for pkg (nil if never recorded)
+func (s *Store) Latest(pkg string) (*parser.App, error) {
+	var app *parser.App
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(latestBucket)).Get([]byte(pkg))
+		if v == nil {
+			return nil
+		}
+		app = &parser.App{}
+		return json.Unmarshal(v, app)
+	})
+
+	return app, err
+}
+
+// Images returns every image URL recorded by a snapshot or a latest app, of
+// any package (the asset store keeps the files they point to)
+func (s *Store) Images() (map[string]bool, error) {
+	images := make(map[string]bool)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(imagesBucket)).ForEach(func(k, _ []byte) error {
+			images[string(k)] = true
+			return nil
+		})
+	})
+
+	return images, err
+}
+
+// indexImages adds urls to the image index
+func indexImages(index *bolt.Bucket, urls []string) error {
+	for _, u := range urls {
+		if u == "" || len(u) > bolt.MaxKeySize {
+			continue
+		}
+		if err := index.Put([]byte(u), []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillImages indexes the images of history recorded before the index
+// existed: every snapshot and every latest app
+func backfillImages(tx *bolt.Tx, index *bolt.Bucket) error {
+	history := tx.Bucket([]byte(bucketName))
+	err := history.ForEachBucket(func(pkg []byte) error {
+		return history.Bucket(pkg).ForEach(func(k, v []byte) error {
+			var snap struct {
+				Images []string `json:"images"`
+			}
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return fmt.Errorf("corrupt snapshot for %s: %v", pkg, err)
+			}
+			return indexImages(index, snap.Images)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket([]byte(latestBucket)).ForEach(func(pkg, v []byte) error {
+		var app parser.App
+		if err := json.Unmarshal(v, &app); err != nil {
+			return fmt.Errorf("corrupt latest app for %s: %v", pkg, err)
+		}
+		return indexImages(index, app.Images())
+	})
+}
+
+// Changes returns only the snapshots in [from, to) that changed something
+func (s *Store) Changes(pkg string, from, to time.Time) ([]Snapshot, error) {
+	snaps, err := s.Snapshots(pkg, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []Snapshot{}
+	for _, snap := range snaps {
+		if len(snap.Changes) > 0 {
+			out = append(out, snap)
+		}
+	}
+	return out, nil
+}
+
+// Snapshots returns the raw snapshots for pkg with from <= timestamp < to
+func (s *Store) Snapshots(pkg string, from, to time.Time) ([]Snapshot, error) {
+	var out []Snapshot
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName)).Bucket([]byte(pkg))
+		if b == nil {
+			return nil
+		}
+
+		end := timeKey(to)
+		c := b.Cursor()
+		for k, v := c.Seek(timeKey(from)); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+			var snap Snapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return fmt.Errorf("corrupt snapshot for %s: %v", pkg, err)
+			}
+			out = append(out, snap)
+		}
+		return nil
+	})
+
+	return out, err
+}
+
+// the times a non-negative int64 of nanoseconds can hold; earlier times
+// would wrap around to the end of the key space
+var (
+	minKeyTime = time.Unix(0, 0)
+	maxKeyTime = time.Unix(0, math.MaxInt64)
+)
+
+// timeKey encodes t as its big-endian UnixNano. Record refuses timestamps
+// outside [minKeyTime, maxKeyTime]; range bounds are clamped to it.
+func timeKey(t time.Time) []byte {
+	n := t.UnixNano()
+	switch {
+	case t.Before(minKeyTime):
+		n = 0
+	case t.After(maxKeyTime):
+		n = math.MaxInt64
+	}
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(n))
+	return k
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// SERIES — downsampled view over a date range
+///////////////////////////////////////////////////////////////////////////////
+
+// ParseRange reads a from/to pair (YYYY-MM-DD or RFC 3339, either may be
+// empty). to defaults to now and a date-only to is inclusive; from defaults
+// to days before to.
+func ParseRange(fromValue, toValue string, days int) (time.Time, time.Time, error) {
+	to := time.Now().UTC()
+	if toValue != "" {
+		t, dateOnly, err := parseTime(toValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %v", err)
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -days)
+	if fromValue != "" {
+		t, _, err := parseTime(fromValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %v", err)
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
+
+func parseTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+// Interval selects the downsampling bucket size
+type Interval string
+
+const (
+	Raw    Interval = "raw"
+	Daily  Interval = "daily"
+	Weekly Interval = "weekly"
+)
+
+// ParseInterval validates the ?interval= query value (raw by default)
+func ParseInterval(v string) (Interval, error) {
+	switch Interval(v) {
+	case "", Raw:
+		return Raw, nil
+	case Daily, Weekly:
+		return Interval(v), nil
+	}
+	return "", fmt.Errorf("interval must be raw, daily or weekly")
+}
+
+// Point is one entry of a series. Rating is the mean over the bucket; the
+// other fields are the last value seen in it.
+type Point struct {
+	Time          time.Time `json:"time"`
+	Samples       int       `json:"samples"`
+	Rating        float64   `json:"rating"`
+	RatingCount   int64     `json:"ratingCount"`
+	Installs      string    `json:"installs"`
+	InstallsCount int64     `json:"installsCount"`
+	Version       string    `json:"version"`
+
+	RatingHistogram parser.Histogram `json:"ratingHistogram,omitempty"`
+}
+
+// Series returns pkg's snapshots in [from, to) downsampled to interval
+func (s *Store) Series(pkg string, from, to time.Time, interval Interval) ([]Point, error) {
+	snaps, err := s.Snapshots(pkg, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []Point{}
+	ratingSum, ratingN := 0.0, 0
+
+	for _, snap := range snaps {
+		start := bucketStart(snap.Timestamp, interval)
+
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(start) {
+			points = append(points, Point{Time: start})
+			ratingSum, ratingN = 0, 0
+		}
+		p := &points[len(points)-1]
+		p.Samples++
+
+		if f, err := strconv.ParseFloat(snap.Rating, 64); err == nil {
+			ratingSum += f
+			ratingN++
+			p.Rating = math.Round(ratingSum/float64(ratingN)*100) / 100
+		}
+		if f, ok := parser.ParseNumber(snap.RatingCount); ok {
+			p.RatingCount = int64(f)
+		}
+		if f, ok := parser.ParseNumber(snap.Installs); ok {
+			p.Installs = snap.Installs
+			p.InstallsCount = int64(f)
+		}
+		if snap.Version != "" && snap.Version != "N.A" {
+			p.Version = snap.Version
+		}
+		if len(snap.RatingHistogram) > 0 {
+			p.RatingHistogram = snap.RatingHistogram
+		}
+	}
+
+	return points, nil
+}
+
+func bucketStart(t time.Time, interval Interval) time.Time {
+	t = t.UTC()
+	switch interval {
+	case Daily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case Weekly:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		offset := (int(day.Weekday()) + 6) % 7 // weeks start on Monday
+		return day.AddDate(0, 0, -offset)
+	}
+	return t
+}
@@ -0,0 +1,112 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSnapshotsRange(t *testing.T) {
+	s := openStore(t)
+	const pkg = "com.example.app"
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 12, 0, 0, 0, time.UTC) }
+
+	for i, rating := range []string{"4.1", "4.2", "4.2"} {
+		if _, err := s.Record(pkg, &parser.App{Rating: rating}, day(i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a pre-1970 timestamp would wrap around to the end of the key space
+	if _, err := s.Record(pkg, &parser.App{Rating: "1.0"}, time.Date(1969, time.July, 20, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("Record accepts a timestamp before 1970")
+	}
+
+	for _, tt := range []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"all", day(1), day(4), 3},
+		{"to is exclusive", day(1), day(3), 2},
+		{"from before 1970", time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC), day(2), 1},
+		{"to after 2262", day(2), time.Date(3000, time.January, 1, 0, 0, 0, 0, time.UTC), 2},
+	} {
+		snaps, err := s.Snapshots(pkg, tt.from, tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snaps) != tt.want {
+			t.Errorf("%s: %d snapshots, want %d", tt.name, len(snaps), tt.want)
+			continue
+		}
+		for i := 1; i < len(snaps); i++ {
+			if !snaps[i-1].Timestamp.Before(snaps[i].Timestamp) {
+				t.Errorf("%s: snapshots out of order: %v", tt.name, snaps)
+			}
+		}
+	}
+
+	snaps, _ := s.Snapshots(pkg, day(1), day(4))
+	if len(snaps) == 3 && (len(snaps[1].Changes) != 1 || len(snaps[2].Changes) != 0) {
+		t.Errorf("changes = %v, %v, want one rating change then none", snaps[1].Changes, snaps[2].Changes)
+	}
+}
+
+func TestImagesIndex(t *testing.T) {
+	s := openStore(t)
+	at := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	record := func(pkg string, app *parser.App) {
+		t.Helper()
+		if _, err := s.Record(pkg, app, at); err != nil {
+			t.Fatal(err)
+		}
+		at = at.Add(time.Hour)
+	}
+	record("com.example.a", &parser.App{Icon: "a-icon", Screenshots: []string{"a-1", "a-2"}})
+	record("com.example.a", &parser.App{Icon: "a-icon-new", Screenshots: []string{"a-2"}})
+	record("com.example.b", &parser.App{Icon: "b-icon", FeatureGraphic: "b-feature"})
+
+	want := map[string]bool{"a-icon": true, "a-icon-new": true, "a-1": true, "a-2": true, "b-icon": true, "b-feature": true}
+	images, err := s.Images()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(images, want) {
+		t.Errorf("Images = %v, want %v", images, want)
+	}
+
+	// history recorded before the index existed is indexed on open
+	err = s.db.Update(func(tx *bolt.Tx) error { return tx.DeleteBucket([]byte(imagesBucket)) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewStore(s.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if images, err := reopened.Images(); err != nil || !reflect.DeepEqual(images, want) {
+		t.Errorf("Images after backfill = %v, %v, want %v", images, err, want)
+	}
+}
@@ -0,0 +1,346 @@
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Layout: bucket "jobs" -> key = job id, value = JSON Job. Bucket
+// "job-items" -> one sub-bucket per job -> key = big-endian position in the
+// submitted list, value = JSON Item (with the parsed app once fetched).
+// Job ids start with the submission time, so key order is queue order.
+const (
+	jobsBucket  = "jobs"
+	itemsBucket = "job-items"
+)
+
+// Job states
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
+)
+
+// Item states
+const (
+	ItemPending   = "pending"
+	ItemDone      = "done"
+	ItemFailed    = "failed"
+	ItemCancelled = "cancelled"
+)
+
+var (
+	ErrNotFound = errors.New("job not found")
+	ErrFinished = errors.New("job has already finished")
+)
+
+// Job is one submitted batch and its progress
+type Job struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Owner      string     `json:"owner,omitempty"` // API key id that submitted it
+	Total      int        `json:"total"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Cancelled  int        `json:"cancelled,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Pending is how many packages are still to be fetched
+func (j Job) Pending() int {
+	return j.Total - j.Succeeded - j.Failed - j.Cancelled
+}
+
+// Finished reports whether the job is done or cancelled
+func (j Job) Finished() bool {
+	return j.Status == StatusDone || j.Status == StatusCancelled
+}
+
+// Item is one package of a job
+type Item struct {
+	Package string      `json:"package"`
+	Status  string      `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	App     *parser.App `json:"app,omitempty"`
+}
+
+// Store persists jobs and their items
+type Store struct {
+	db *storage.DB
+}
+
+// NewStore prepares the job buckets in db
+func NewStore(db *storage.DB) (*Store, error) {
+	if err := db.EnsureBuckets(jobsBucket, itemsBucket); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Create queues a job for pkgs
+func (s *Store) Create(pkgs []string, owner string) (Job, error) {
+	now := time.Now().UTC()
+	job := Job{
+		ID:        newID(now),
+		Status:    StatusQueued,
+		Owner:     owner,
+		Total:     len(pkgs),
+		CreatedAt: now,
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket([]byte(itemsBucket)).CreateBucket([]byte(job.ID))
+		if err != nil {
+			return err
+		}
+		for i, pkg := range pkgs {
+			data, err := json.Marshal(Item{Package: pkg, Status: ItemPending})
+			if err != nil {
+				return err
+			}
+			if err := b.Put(itemKey(i), data); err != nil {
+				return err
+			}
+		}
+		return putJob(tx, job)
+	})
+	return job, err
+}
+
+// Get returns one job (ErrNotFound if it doesn't exist)
+func (s *Store) Get(id string) (Job, error) {
+	var job Job
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		job, err = getJob(tx, id)
+		return err
+	})
+	return job, err
+}
+
+// List returns every job, newest first; status filters by state ("" = all)
+func (s *Store) List(status string) ([]Job, error) {
+	out := []Job{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(jobsBucket)).ForEach(func(k, v []byte) error {
+			var job Job
+			if err := json.Unmarshal(v, &job); err != nil {
+				return fmt.Errorf("corrupt job %s: %v", k, err)
+			}
+			if status == "" || job.Status == status {
+				out = append(out, job)
+			}
+			return nil
+		})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	return out, err
+}
+
+// Items returns a job's packages in submission order; withApps includes the
+// parsed apps (the status view leaves them out)
+func (s *Store) Items(id string, withApps bool) ([]Item, error) {
+	items := []Item{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(itemsBucket)).Bucket([]byte(id))
+		if b == nil {
+			return ErrNotFound
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var it Item
+			if err := json.Unmarshal(v, &it); err != nil {
+				return fmt.Errorf("corrupt job item %s/%d: %v", id, binary.BigEndian.Uint32(k), err)
+			}
+			if !withApps {
+				it.App = nil
+			}
+			items = append(items, it)
+			return nil
+		})
+	})
+	return items, err
+}
+
+// Delete removes a job and its items
+func (s *Store) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket([]byte(itemsBucket)).DeleteBucket([]byte(id)); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		return tx.Bucket([]byte(jobsBucket)).Delete([]byte(id))
+	})
+}
+
+// nextQueued returns the oldest queued job
+func (s *Store) nextQueued() (Job, bool, error) {
+	var job Job
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(jobsBucket)).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if err := json.Unmarshal(v, &job); err != nil {
+				return fmt.Errorf("corrupt job %s: %v", k, err)
+			}
+			if job.Status == StatusQueued {
+				found = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return job, found, err
+}
+
+// pending returns the positions and packages not fetched yet
+func (s *Store) pending(id string) ([]int, []string, error) {
+	var idx []int
+	var pkgs []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(itemsBucket)).Bucket([]byte(id))
+		if b == nil {
+			return ErrNotFound
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var it Item
+			if err := json.Unmarshal(v, &it); err != nil {
+				return err
+			}
+			if it.Status == ItemPending {
+				idx = append(idx, int(binary.BigEndian.Uint32(k)))
+				pkgs = append(pkgs, it.Package)
+			}
+			return nil
+		})
+	})
+	return idx, pkgs, err
+}
+
+// finishItem stores one package's outcome, bumps the job's counters and
+// returns the job
+func (s *Store) finishItem(id string, i int, it Item) (Job, error) {
+	var job Job
+	data, err := json.Marshal(it)
+	if err != nil {
+		return job, err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if job, err = getJob(tx, id); err != nil {
+			return err
+		}
+		b := tx.Bucket([]byte(itemsBucket)).Bucket([]byte(id))
+		if b == nil {
+			return ErrNotFound
+		}
+		if err := b.Put(itemKey(i), data); err != nil {
+			return err
+		}
+		if it.Status == ItemDone {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		return putJob(tx, job)
+	})
+	return job, err
+}
+
+// update loads a job, applies fn and saves it
+func (s *Store) update(id string, fn func(tx *bolt.Tx, job *Job) error) (Job, error) {
+	var job Job
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if job, err = getJob(tx, id); err != nil {
+			return err
+		}
+		if err := fn(tx, &job); err != nil {
+			return err
+		}
+		return putJob(tx, job)
+	})
+	return job, err
+}
+
+// cancelPending marks every pending item of a job cancelled and returns how
+// many there were
+func cancelPending(tx *bolt.Tx, id string) (int, error) {
+	b := tx.Bucket([]byte(itemsBucket)).Bucket([]byte(id))
+	if b == nil {
+		return 0, nil
+	}
+
+	// collect first: a bucket mustn't be written while a cursor walks it
+	updates := make(map[string][]byte)
+	err := b.ForEach(func(k, v []byte) error {
+		var it Item
+		if err := json.Unmarshal(v, &it); err != nil {
+			return err
+		}
+		if it.Status != ItemPending {
+			return nil
+		}
+		it.Status = ItemCancelled
+		data, err := json.Marshal(it)
+		if err != nil {
+			return err
+		}
+		updates[string(k)] = data
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for k, data := range updates {
+		if err := b.Put([]byte(k), data); err != nil {
+			return 0, err
+		}
+	}
+	return len(updates), nil
+}
+
+func getJob(tx *bolt.Tx, id string) (Job, error) {
+	var job Job
+	v := tx.Bucket([]byte(jobsBucket)).Get([]byte(id))
+	if v == nil {
+		return job, ErrNotFound
+	}
+	err := json.Unmarshal(v, &job)
+	return job, err
+}
+
+func putJob(tx *bolt.Tx, job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(jobsBucket)).Put([]byte(job.ID), data)
+}
+
+func itemKey(i int) []byte {
+	k := make([]byte, 4)
+	binary.BigEndian.PutUint32(k, uint32(i))
+	return k
+}
+
+// newID is the submission time (so ids sort in queue order) plus random bits
+func newID(at time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return strings.Replace(at.Format("20060102-150405.000000"), ".", "", 1) + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,231 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// start runs m until the test ends
+func start(t *testing.T, m *Manager) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+// waitFor polls the job until cond holds
+func waitFor(t *testing.T, s *Store, id string, cond func(Job) bool) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := s.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cond(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for job %s: %+v", id, job)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func finished(j Job) bool { return j.Finished() }
+
+func fetchOK(ctx context.Context, pkg string) (*parser.App, error) {
+	if pkg == "com.example.missing" {
+		return nil, errors.New("app not found on Play Store")
+	}
+	return &parser.App{Title: pkg}, nil
+}
+
+func TestJobRuns(t *testing.T) {
+	s := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+	m := NewManager(s, fetchOK, 2)
+	start(t, m)
+
+	job, err := m.Submit([]string{"com.example.a", "com.example.missing", "com.example.b"}, "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job = waitFor(t, s, job.ID, finished)
+
+	if job.Status != StatusDone || job.Succeeded != 2 || job.Failed != 1 || job.Pending() != 0 {
+		t.Errorf("job = %+v, want done with 2 succeeded and 1 failed", job)
+	}
+	if job.Owner != "key1" || job.StartedAt == nil || job.FinishedAt == nil {
+		t.Errorf("job = %+v, want owner and timestamps", job)
+	}
+
+	items, err := s.Items(job.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ pkg, status string }{
+		{"com.example.a", ItemDone}, {"com.example.missing", ItemFailed}, {"com.example.b", ItemDone},
+	}
+	for i, w := range want {
+		if items[i].Package != w.pkg || items[i].Status != w.status {
+			t.Errorf("item %d = %s %s, want %s %s", i, items[i].Package, items[i].Status, w.pkg, w.status)
+		}
+	}
+	if items[0].App == nil || items[0].App.Title != "com.example.a" || items[1].Error == "" {
+		t.Errorf("items = %+v, want the app and the error", items)
+	}
+
+	if _, err := m.Cancel(job.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("cancel finished job: err = %v, want ErrFinished", err)
+	}
+	if _, err := m.Cancel("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("cancel unknown job: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	s := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+
+	// the first package returns, the rest block until cancelled
+	var calls atomic.Int32
+	m := NewManager(s, func(ctx context.Context, pkg string) (*parser.App, error) {
+		if calls.Add(1) == 1 {
+			return &parser.App{Title: pkg}, nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, 1)
+	start(t, m)
+
+	running, err := m.Submit([]string{"com.example.a", "com.example.b", "com.example.c"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued, err := m.Submit([]string{"com.example.d"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, s, running.ID, func(j Job) bool { return j.Succeeded == 1 })
+
+	// queued jobs are cancelled without running
+	job, err := m.Cancel(queued.ID)
+	if err != nil || job.Status != StatusCancelled || job.Cancelled != 1 {
+		t.Fatalf("cancel queued: %+v, %v", job, err)
+	}
+
+	job, err = m.Cancel(running.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusCancelled || job.Succeeded != 1 || job.Cancelled != 2 || job.FinishedAt == nil {
+		t.Errorf("cancelled job = %+v, want 1 succeeded and 2 cancelled", job)
+	}
+
+	items, _ := s.Items(running.ID, false)
+	if items[0].Status != ItemDone || items[1].Status != ItemCancelled || items[2].Status != ItemCancelled {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestJobResumesAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	// first process: fetches one package, then shuts down mid-job
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var fetched atomic.Int32
+	m := NewManager(s, func(fctx context.Context, pkg string) (*parser.App, error) {
+		if fetched.Add(1) == 1 {
+			return &parser.App{Title: pkg}, nil
+		}
+		cancel()
+		<-fctx.Done()
+		return nil, fctx.Err()
+	}, 1)
+	job, err := m.Submit([]string{"com.example.a", "com.example.b", "com.example.c"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Run(ctx)
+	db.Close()
+
+	// second process: picks the job up again and fetches only what's left
+	s = openStore(t, path)
+	var refetched []string
+	m = NewManager(s, func(ctx context.Context, pkg string) (*parser.App, error) {
+		refetched = append(refetched, pkg)
+		return &parser.App{Title: pkg}, nil
+	}, 1)
+	start(t, m)
+
+	job = waitFor(t, s, job.ID, finished)
+	if job.Status != StatusDone || job.Succeeded != 3 {
+		t.Errorf("job = %+v, want done with 3 succeeded", job)
+	}
+	if len(refetched) != 2 || refetched[0] != "com.example.b" {
+		t.Errorf("fetched after restart = %v, want the two pending packages", refetched)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	s := openStore(t, filepath.Join(t.TempDir(), "jobs.db"))
+	m := NewManager(s, fetchOK, 1)
+	m.Retention = time.Hour
+
+	old, _ := s.Create([]string{"com.example.a"}, "")
+	recent, _ := s.Create([]string{"com.example.b"}, "")
+	for id, age := range map[string]time.Duration{old.ID: 2 * time.Hour, recent.ID: time.Minute} {
+		finishedAt := time.Now().Add(-age)
+		s.update(id, func(_ *bolt.Tx, j *Job) error {
+			j.Status, j.FinishedAt = StatusDone, &finishedAt
+			return nil
+		})
+	}
+
+	m.prune()
+	if _, err := s.Get(old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old job: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Items(old.ID, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old job items: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Get(recent.ID); err != nil {
+		t.Errorf("recent job: %v", err)
+	}
+}
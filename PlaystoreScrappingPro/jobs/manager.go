@@ -0,0 +1,334 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FetchFunc fetches and parses one package
+type FetchFunc func(ctx context.Context, pkg string) (*parser.App, error)
+
+// Manager runs queued jobs one at a time, oldest first, fetching up to
+// Concurrency packages of a job at once. Every outcome is saved as soon as it
+// is known, so a job interrupted by a restart resumes with the packages it
+// hadn't fetched yet.
+type Manager struct {
+	Store       *Store
+	Fetch       FetchFunc
+	Concurrency int
+	Retention   time.Duration // finished jobs older than this are deleted (0 = kept)
+
+	wake chan struct{}
+
+	subMu sync.Mutex
+	subs  map[string]map[chan Progress]bool // job id -> subscribers
+
+	mu       sync.Mutex
+	running  string // id of the running job
+	cancel   context.CancelFunc
+	stopping bool          // Cancel was called for the running job
+	done     chan struct{} // closed once the running job has settled
+}
+
+var errNotQueued = errors.New("job is no longer queued")
+
+// NewManager returns a manager running store's jobs with fetch
+func NewManager(store *Store, fetch FetchFunc, concurrency int) *Manager {
+	return &Manager{
+		Store:       store,
+		Fetch:       fetch,
+		Concurrency: concurrency,
+		wake:        make(chan struct{}, 1),
+		subs:        make(map[string]map[chan Progress]bool),
+	}
+}
+
+// Progress is sent to subscribers as a job advances: one per package with
+// Item set, then a last one with Item nil once the job is done or cancelled
+type Progress struct {
+	Index int   `json:"index"`
+	Item  *Item `json:"item,omitempty"`
+	Job   Job   `json:"job"`
+}
+
+// Subscribe returns a channel receiving job id's progress, and a function to
+// stop. Progress is dropped rather than blocking the job when a subscriber
+// falls behind, so consumers should reconcile with Store.Items at the end.
+func (m *Manager) Subscribe(id string) (<-chan Progress, func()) {
+	ch := make(chan Progress, 256)
+
+	m.subMu.Lock()
+	if m.subs[id] == nil {
+		m.subs[id] = make(map[chan Progress]bool)
+	}
+	m.subs[id][ch] = true
+	m.subMu.Unlock()
+
+	return ch, func() {
+		m.subMu.Lock()
+		delete(m.subs[id], ch)
+		if len(m.subs[id]) == 0 {
+			delete(m.subs, id)
+		}
+		m.subMu.Unlock()
+	}
+}
+
+func (m *Manager) publish(p Progress) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	for ch := range m.subs[p.Job.ID] {
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+// Submit queues a job for pkgs; owner is recorded with it
+func (m *Manager) Submit(pkgs []string, owner string) (Job, error) {
+	job, err := m.Store.Create(pkgs, owner)
+	if err != nil {
+		return job, err
+	}
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Cancel stops a queued or running job. Packages fetched so far keep their
+// results; the rest are marked cancelled. It returns ErrNotFound or
+// ErrFinished when there is nothing to cancel.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	if m.running == id {
+		m.stopping = true
+		m.cancel()
+		done := m.done
+		m.mu.Unlock()
+
+		<-done
+		return m.Store.Get(id)
+	}
+	defer m.mu.Unlock()
+
+	job, err := m.Store.update(id, func(tx *bolt.Tx, job *Job) error {
+		if job.Finished() {
+			return ErrFinished
+		}
+		n, err := cancelPending(tx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		job.Status = StatusCancelled
+		job.Cancelled = n
+		job.FinishedAt = &now
+		return nil
+	})
+	if err == nil {
+		m.publish(Progress{Job: job})
+	}
+	return job, err
+}
+
+// Run processes the queue until ctx is cancelled. Jobs left running by a
+// previous process are queued again first.
+func (m *Manager) Run(ctx context.Context) {
+	if err := m.requeue(); err != nil {
+		slog.Error("requeueing interrupted jobs failed", "error", err)
+	}
+
+	prune := time.NewTicker(time.Hour)
+	defer prune.Stop()
+	m.prune()
+
+	for ctx.Err() == nil {
+		job, ok, err := m.Store.nextQueued()
+		if err != nil {
+			slog.Error("reading job queue failed", "error", err)
+		}
+		if ok {
+			m.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-m.wake:
+		case <-prune.C:
+			m.prune()
+		}
+	}
+}
+
+// run fetches a job's pending packages and settles its final state
+func (m *Manager) run(ctx context.Context, job Job) {
+	id := job.ID
+	jctx, cancel := context.WithCancel(logging.WithRequestID(ctx, "job-"+id))
+	defer cancel()
+
+	m.mu.Lock()
+	job, err := m.Store.update(id, func(tx *bolt.Tx, j *Job) error {
+		if j.Status != StatusQueued {
+			return errNotQueued // cancelled since it was picked
+		}
+		now := time.Now().UTC()
+		j.Status = StatusRunning
+		if j.StartedAt == nil {
+			j.StartedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		m.mu.Unlock()
+		if !errors.Is(err, errNotQueued) {
+			slog.Error("starting job failed", "job", id, "error", err)
+		}
+		return
+	}
+	done := make(chan struct{})
+	m.running, m.cancel, m.stopping, m.done = id, cancel, false, done
+	m.mu.Unlock()
+
+	logger := logging.From(jctx)
+	logger.Info("job started", "packages", job.Total, "pending", job.Pending())
+	start := time.Now()
+
+	m.fetchPending(jctx, id)
+
+	// settle under the lock so a concurrent Cancel either sees the job
+	// running or finished, never in between
+	m.mu.Lock()
+	job, err = m.Store.update(id, func(tx *bolt.Tx, j *Job) error {
+		now := time.Now().UTC()
+		switch {
+		case m.stopping:
+			n, err := cancelPending(tx, j.ID)
+			if err != nil {
+				return err
+			}
+			j.Status = StatusCancelled
+			j.Cancelled = n
+			j.FinishedAt = &now
+		case ctx.Err() != nil:
+			j.Status = StatusQueued // shutting down: resume on the next start
+		default:
+			j.Status = StatusDone
+			j.FinishedAt = &now
+		}
+		return nil
+	})
+	m.running, m.cancel, m.stopping, m.done = "", nil, false, nil
+	m.mu.Unlock()
+	close(done)
+
+	if err != nil {
+		logger.Error("saving job state failed", "error", err)
+		return
+	}
+	if job.Finished() {
+		m.publish(Progress{Job: job})
+	}
+	logger.Info("job "+job.Status, "succeeded", job.Succeeded, "failed", job.Failed,
+		"cancelled", job.Cancelled, "duration_ms", time.Since(start).Milliseconds())
+}
+
+// fetchPending fetches the job's pending packages, saving each outcome, until
+// they are all done or ctx is cancelled
+func (m *Manager) fetchPending(ctx context.Context, id string) {
+	idx, pkgs, err := m.Store.pending(id)
+	if err != nil {
+		logging.From(ctx).Error("loading job packages failed", "error", err)
+		return
+	}
+
+	sem := make(chan struct{}, max(m.Concurrency, 1))
+	var wg sync.WaitGroup
+
+	for n, pkg := range pkgs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, pkg string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			app, err := m.Fetch(ctx, pkg)
+			if ctx.Err() != nil {
+				return // interrupted: stays pending
+			}
+
+			it := Item{Package: pkg, Status: ItemDone, App: app}
+			if err != nil {
+				it = Item{Package: pkg, Status: ItemFailed, Error: err.Error()}
+			}
+			job, err := m.Store.finishItem(id, i, it)
+			if err != nil {
+				logging.From(ctx).Error("saving job result failed", "package", pkg, "error", err)
+				return
+			}
+			m.publish(Progress{Index: i, Item: &it, Job: job})
+		}(idx[n], pkg)
+	}
+
+	wg.Wait()
+}
+
+// requeue puts jobs a previous process was running back in the queue
+func (m *Manager) requeue() error {
+	running, err := m.Store.List(StatusRunning)
+	if err != nil {
+		return err
+	}
+	for _, job := range running {
+		_, err := m.Store.update(job.ID, func(tx *bolt.Tx, j *Job) error {
+			j.Status = StatusQueued
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		slog.Info("job resumed after restart", "job", job.ID, "pending", job.Pending())
+	}
+	return nil
+}
+
+// prune deletes finished jobs older than Retention
+func (m *Manager) prune() {
+	if m.Retention <= 0 {
+		return
+	}
+
+	all, err := m.Store.List("")
+	if err != nil {
+		slog.Error("listing jobs failed", "error", err)
+		return
+	}
+
+	cutoff := time.Now().Add(-m.Retention)
+	for _, job := range all {
+		if !job.Finished() || job.FinishedAt == nil || job.FinishedAt.After(cutoff) {
+			continue
+		}
+		if err := m.Store.Delete(job.ID); err != nil {
+			slog.Error("deleting old job failed", "job", job.ID, "error", err)
+		}
+	}
+}
@@ -1,54 +1,70 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/alerts"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/apikeys"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/assets"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/compare"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/gql"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/health"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/history"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/jobs"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/metrics"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/middleware"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/openapi"
 	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/output"
 	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
 	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/scraper"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/selftest"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/watchlist"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
 // SECURITY — sanitize and validate package name
 ///////////////////////////////////////////////////////////////////////////////
 
+// sanitizePackage is scraper.ValidatePackage; cmd/record-fixtures uses the
+// same rules for the file names it writes
 func sanitizePackage(pkg string) (string, error) {
+	return scraper.ValidatePackage(pkg)
+}
 
-	pkg = strings.TrimSpace(pkg)
-	pkg = strings.ToLower(pkg)
-
-	if pkg == "" {
-		return "", fmt.Errorf("package name is required")
-	}
-
-	if len(pkg) > 60 {
-		return "", fmt.Errorf("package name too long")
-	}
-
-	if !strings.Contains(pkg, ".") {
-		return "", fmt.Errorf("invalid package format (use com.example.app)")
-	}
+// sanitizeCountry validates a storefront country (gl): two letters, ""
+// meaning scraper.DefaultCountry
+func sanitizeCountry(country string) (string, error) {
 
-	if strings.ContainsAny(pkg, "/\\?*&=<>'\"{}()[]|;: ") {
-		return "", fmt.Errorf("unsafe characters detected")
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return scraper.DefaultCountry, nil
 	}
 
-	for _, c := range pkg {
-		if !(c >= 'a' && c <= 'z') &&
-			!(c >= '0' && c <= '9') &&
-			c != '.' {
-			return "", fmt.Errorf("invalid character in package name")
-		}
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return "", fmt.Errorf("country must be a two-letter code (e.g. US, IN)")
 	}
 
-	return pkg, nil
+	return country, nil
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -65,12 +81,32 @@ var cacheLock = &sync.RWMutex{}
 
 const CacheTTL = 6 * 60 * 60 // 6 hours
 
+// ReviewsEntry is the cached reviews page of a package
+type ReviewsEntry struct {
+	Reviews   []parser.Review
+	Timestamp int64
+}
+
+// ReviewsCache shares cacheLock and CacheTTL with Cache
+var ReviewsCache = make(map[string]ReviewsEntry)
+
+// SearchEntry is the cached results of a search
+type SearchEntry struct {
+	Results   []parser.SearchResult
+	Timestamp int64
+}
+
+// SearchCache is keyed by the lowercased query; it shares cacheLock and
+// CacheTTL with Cache
+var SearchCache = make(map[string]SearchEntry)
+
 func getFromCache(pkg string) (*parser.App, bool) {
 	cacheLock.RLock()
 	entry, found := Cache[pkg]
 	cacheLock.RUnlock()
 
 	if !found {
+		metrics.CacheMisses.Inc()
 		return nil, false
 	}
 
@@ -78,9 +114,12 @@ func getFromCache(pkg string) (*parser.App, bool) {
 		cacheLock.Lock()
 		delete(Cache, pkg)
 		cacheLock.Unlock()
+		metrics.CacheEvictions.Inc()
+		metrics.CacheMisses.Inc()
 		return nil, false
 	}
 
+	metrics.CacheHits.Inc()
 	return entry.Data, true
 }
 
@@ -93,73 +132,851 @@ func saveToCache(pkg string, app *parser.App) {
 	cacheLock.Unlock()
 }
 
+///////////////////////////////////////////////////////////////////////////////
+// FETCH — cache check + retry scraper + parse (shared by HTML and API routes)
+///////////////////////////////////////////////////////////////////////////////
+
+var errUpstream = errors.New("failed to reach Google Play, try again")
+
+// Wait between scrape attempts; a 429's Retry-After is honoured up to
+// maxRetryAfter
+var (
+	retryDelay    = time.Second
+	maxRetryAfter = 10 * time.Second
+)
+
+// historyStore records a snapshot of every successful parse (nil = disabled)
+var historyStore *history.Store
+
+// watchStore holds the packages refreshed by the scheduler
+var watchStore *watchlist.Store
+
+// alertStore holds alert rules and the webhook delivery log
+var alertStore *alerts.Store
+var alertNotifier *alerts.Notifier
+
+// jobManager runs the asynchronous batch jobs of /api/jobs
+var jobManager *jobs.Manager
+
+// assetStore mirrors app images to this server (nil = disabled, see ASSETS_DIR)
+var assetStore *assets.Store
+
+func fetchApp(ctx context.Context, pkg string) (*parser.App, error) {
+
+	// CACHE CHECK
+	if app, ok := getFromCache(pkg); ok {
+		logging.From(ctx).Debug("cache hit", "package", pkg)
+		return app, nil
+	}
+
+	return scrapeApp(ctx, pkg)
+}
+
+// fetchAppIn is fetchApp on the storefront of another country, whose prices
+// differ. Those pages are cached under pkg@country and never recorded in
+// history, so snapshots and alerts keep comparing one storefront.
+func fetchAppIn(ctx context.Context, pkg, country string) (*parser.App, error) {
+
+	if country == scraper.DefaultCountry {
+		return fetchApp(ctx, pkg)
+	}
+	key := pkg + "@" + country
+	if app, ok := getFromCache(key); ok {
+		logging.From(ctx).Debug("cache hit", "package", pkg, "country", country)
+		return app, nil
+	}
+
+	logger := logging.From(ctx).With("package", pkg, "country", country)
+	fetcher := &scraper.Fetcher{Country: country}
+	doc, err := fetchWithRetry(ctx, pkg, fetcher.AppHTML)
+	if errors.Is(err, scraper.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("scrape failed", "error", err)
+		return nil, errUpstream
+	}
+
+	app, err := parser.ParsePlayStoreHTMLContext(ctx, doc)
+	metrics.ObserveParse(app)
+	if err != nil {
+		return nil, err
+	}
+	app = app.InCountry(country)
+	if assetStore != nil {
+		app = assetStore.Mirror(ctx, app)
+	}
+
+	saveToCache(key, app)
+	logger.Info("scraped")
+	return app, nil
+}
+
+// scrapeApp always goes to Google Play (used directly by the watchlist
+// scheduler to refresh the cache)
+func scrapeApp(ctx context.Context, pkg string) (*parser.App, error) {
+
+	logger := logging.From(ctx).With("package", pkg)
+	start := time.Now()
+
+	doc, err := fetchWithRetry(ctx, pkg, scraper.FetchPlayStoreHTML)
+	if errors.Is(err, scraper.ErrNotFound) {
+		logger.Info("app not found upstream")
+		notifyAlerts(ctx, alerts.Event{Package: pkg, NotFound: true, At: time.Now()})
+		return nil, err
+	}
+	if err != nil {
+		logger.Error("scrape failed", "error", err, "duration_ms", time.Since(start).Milliseconds())
+		return nil, errUpstream
+	}
+
+	// PARSE APP
+	app, err := parser.ParsePlayStoreHTMLContext(ctx, doc)
+	metrics.ObserveParse(app)
+	if err != nil {
+		notifyAlerts(ctx, alerts.Event{Package: pkg, NotFound: true, At: time.Now()})
+		return nil, err
+	}
+	app = app.InCountry(scraper.DefaultCountry)
+
+	// MIRROR IMAGES (before caching, so the cache and history hold local URLs)
+	if assetStore != nil {
+		app = assetStore.Mirror(ctx, app)
+	}
+
+	// SAVE TO CACHE
+	saveToCache(pkg, app)
+	logger.Info("scraped", "duration_ms", time.Since(start).Milliseconds())
+
+	// HISTORY SNAPSHOT + ALERT RULES
+	ev := alerts.Event{Package: pkg, App: app, At: time.Now()}
+	if historyStore != nil {
+		snap, err := historyStore.Record(pkg, app, ev.At)
+		if err != nil {
+			logger.Error("history snapshot failed", "error", err)
+		}
+		ev.Changes = snap.Changes
+		if len(snap.Changes) > 0 {
+			logger.Info("app changed", "changes", len(snap.Changes))
+		}
+	}
+	notifyAlerts(ctx, ev)
+
+	return app, nil
+}
+
+// fetchWithRetry runs fetch up to 3 times; a 404 or an oversized page is final
+func fetchWithRetry(ctx context.Context, pkg string, fetch func(context.Context, string) (*goquery.Document, error)) (*goquery.Document, error) {
+	logger := logging.From(ctx).With("package", pkg)
+
+	var doc *goquery.Document
+	var err error
+	for retry := 1; retry <= 3; retry++ {
+		doc, err = fetch(ctx, pkg)
+		if err == nil || errors.Is(err, scraper.ErrNotFound) || errors.Is(err, scraper.ErrPageTooLarge) || ctx.Err() != nil {
+			break
+		}
+		logger.Warn("scrape attempt failed", "attempt", retry, "error", err)
+		if retry < 3 {
+			metrics.ScrapeRetries.Inc()
+			if !sleepCtx(ctx, retryWait(err)) {
+				break
+			}
+		}
+	}
+	return doc, err
+}
+
+// fetchReviews returns the reviews shown for pkg (cache first; they are a
+// separate page, only fetched when asked for)
+func fetchReviews(ctx context.Context, pkg string) ([]parser.Review, error) {
+	cacheLock.RLock()
+	entry, ok := ReviewsCache[pkg]
+	cacheLock.RUnlock()
+	if ok && time.Now().Unix()-entry.Timestamp <= CacheTTL {
+		return entry.Reviews, nil
+	}
+
+	doc, err := fetchWithRetry(ctx, pkg, scraper.FetchReviewsHTML)
+	if errors.Is(err, scraper.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		logging.From(ctx).Error("reviews scrape failed", "package", pkg, "error", err)
+		return nil, errUpstream
+	}
+
+	reviews := parser.ParseReviews(doc)
+	cacheLock.Lock()
+	ReviewsCache[pkg] = ReviewsEntry{Reviews: reviews, Timestamp: time.Now().Unix()}
+	cacheLock.Unlock()
+	return reviews, nil
+}
+
+// fetchSearch returns the apps the store lists for query (cache first)
+func fetchSearch(ctx context.Context, query string) ([]parser.SearchResult, error) {
+	key := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	cacheLock.RLock()
+	entry, ok := SearchCache[key]
+	cacheLock.RUnlock()
+	if ok && time.Now().Unix()-entry.Timestamp <= CacheTTL {
+		return entry.Results, nil
+	}
+
+	doc, err := fetchWithRetry(ctx, key, scraper.FetchSearchHTML)
+	if err != nil {
+		logging.From(ctx).Error("search scrape failed", "query", key, "error", err)
+		return nil, errUpstream
+	}
+
+	results := parser.ParseSearch(doc)
+	cacheLock.Lock()
+	SearchCache[key] = SearchEntry{Results: results, Timestamp: time.Now().Unix()}
+	cacheLock.Unlock()
+	return results, nil
+}
+
+// retryWait is how long to wait before retrying after err
+func retryWait(err error) time.Duration {
+	var throttled *scraper.ThrottledError
+	if errors.As(err, &throttled) && throttled.RetryAfter > retryDelay {
+		return min(throttled.RetryAfter, maxRetryAfter)
+	}
+	return retryDelay
+}
+
+// sleepCtx waits d, returning false if ctx was cancelled first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// alertEngine evaluates alert rules after every scrape (nil = disabled)
+var alertEngine *alerts.Engine
+
+func notifyAlerts(ctx context.Context, ev alerts.Event) {
+	if alertEngine != nil {
+		// webhook retries must not block the scrape, nor die with the request
+		go alertEngine.Process(context.WithoutCancel(ctx), ev)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CONFIG — environment overrides
+///////////////////////////////////////////////////////////////////////////////
+
+const (
+	DefaultWatchInterval = 6 * time.Hour
+	DefaultWatchJitter   = 10 * time.Minute
+	DefaultJobRetention  = 7 * 24 * time.Hour
+	DefaultAssetGC       = 24 * time.Hour
+	DefaultAssetGrace    = time.Hour
+)
+
+// envString reads a string from the environment
+func envString(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// envDuration reads a positive duration like "30m" or "6h" from the environment
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s: invalid duration %q (must be positive)", name, v)
+	}
+	return d
+}
+
+// envDurationOrOff is envDuration for settings where "0" turns the feature off
+func envDurationOrOff(name string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d == 0 {
+		return 0
+	}
+	return envDuration(name, def)
+}
+
+// envInt reads a non-negative integer from the environment
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("%s: invalid number %q", name, v)
+	}
+	return n
+}
+
+// envFloat reads a number from the environment
+func envFloat(name string, def float64) float64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		log.Fatalf("%s: invalid number %q", name, v)
+	}
+	return f
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// API KEYS — API_KEYS=name:secret,..., API_ADMIN_KEY, or POST /admin/keys
+///////////////////////////////////////////////////////////////////////////////
+
+// apiKeys and apiGuard protect the API (nil = API_AUTH=off)
+var (
+	apiKeys  *apikeys.Store
+	apiGuard *apikeys.Guard
+)
+
+// setupAPIKeys loads the stored keys, adds the configured ones and builds
+// the guard. Anonymous callers of the HTML pages get anon limits per IP.
+func setupAPIKeys(db *storage.DB, limits, anon apikeys.Limits, configured map[string]string, adminKey string) error {
+	store, err := apikeys.NewStore(db, limits)
+	if err != nil {
+		return err
+	}
+	for name, secret := range configured {
+		if err := store.AddConfigKey(name, secret, false); err != nil {
+			return err
+		}
+	}
+	if adminKey != "" {
+		if err := store.AddConfigKey("admin", adminKey, true); err != nil {
+			return err
+		}
+	}
+
+	apiKeys = store
+	apiGuard = apikeys.NewGuard(store, anon)
+	return nil
+}
+
+// trustedProxies may set X-Forwarded-For (TRUSTED_PROXIES, comma-separated
+// IPs or CIDRs). nil trusts none, so the per-IP limits of anonymous callers
+// see the connection's address and can't be dodged with a forged header.
+var trustedProxies []string
+
+// openRoute limits anonymous visitors of an HTML page that triggers scraping
+func openRoute() gin.HandlerFunc {
+	if apiGuard == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return apiGuard.AllowAnonymous(output.ShowErrorPage)
+}
+
+// formRoute requires an API key, posted with the form, on an HTML form
+// that changes state (e.g. adds scheduled scraping)
+func formRoute() gin.HandlerFunc {
+	if apiGuard == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return apiGuard.RequireKeyForm(output.ShowErrorPage)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PARSER RULES — PARSER_RULES_PATH, reloaded on SIGHUP
+///////////////////////////////////////////////////////////////////////////////
+
+var errNoRulesFile = errors.New("no rules file configured (set PARSER_RULES_PATH)")
+
+// rulesPath is the selector rules file ("" = the rules bundled with the parser)
+var rulesPath string
+
+// reloadRules loads rulesPath and swaps it in; on error the current rules stay
+func reloadRules() (*parser.Rules, error) {
+	if rulesPath == "" {
+		return nil, errNoRulesFile
+	}
+
+	rules, err := parser.LoadRulesFile(rulesPath)
+	if err != nil {
+		slog.Error("parser rules reload failed", "path", rulesPath, "error", err)
+		return nil, err
+	}
+
+	old := parser.CurrentRules().Version
+	parser.SetRules(rules)
+	slog.Info("parser rules loaded", "path", rulesPath, "version", rules.Version, "previous_version", old)
+	return rules, nil
+}
+
+// reloadRulesOnSignal reloads the rules file on every SIGHUP
+func reloadRulesOnSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		reloadRules()
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// READINESS CHECKS
+///////////////////////////////////////////////////////////////////////////////
+
+// requiredTemplates are the pages every HTML route renders
+var requiredTemplates = []string{
+	"index.html", "result.html", "error.html", "compare.html", "changes.html", "watchlist.html",
+}
+
+// checkCache fails if the cache lock can't be read-locked before ctx ends,
+// i.e. a writer is holding it for longer than the readiness timeout
+func checkCache(ctx context.Context) error {
+	for !cacheLock.TryRLock() {
+		select {
+		case <-ctx.Done():
+			return errors.New("cache lock held by a writer")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cacheLock.RUnlock()
+	return nil
+}
+
+// checkTemplates verifies every required page template was loaded
+func checkTemplates(r *gin.Engine) health.Check {
+	return func(ctx context.Context) error {
+		if r.HTMLRender == nil {
+			return errors.New("templates not loaded")
+		}
+		for _, name := range requiredTemplates {
+			h, ok := r.HTMLRender.Instance(name, nil).(render.HTML)
+			if !ok || h.Template == nil || h.Template.Lookup(name) == nil {
+				return fmt.Errorf("template %s not loaded", name)
+			}
+		}
+		return nil
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // MAIN SERVER
 ///////////////////////////////////////////////////////////////////////////////
 
 func main() {
 
-	r := gin.Default()
+	// LOGGING (LOG_FORMAT=text|json, LOG_LEVEL=debug|info|warn|error)
+	if err := logging.Setup(os.Stderr, os.Getenv("LOG_FORMAT"), envString("LOG_LEVEL", "info")); err != nil {
+		log.Fatalf("logging: %v", err)
+	}
+
+	db, err := storage.Open(storage.PathFromEnv())
+	if err != nil {
+		log.Fatalf("storage: %v", err)
+	}
+	defer db.Close()
+
+	if err := openStores(db); err != nil {
+		log.Fatal(err)
+	}
+
+	// UPSTREAM (PLAYSTORE_BASE_URL points the scraper at e.g. cmd/fakestore)
+	if u := os.Getenv("PLAYSTORE_BASE_URL"); u != "" {
+		if err := scraper.SetBaseURL(u); err != nil {
+			log.Fatalf("PLAYSTORE_BASE_URL: %v", err)
+		}
+	}
+
+	// API KEYS (API_AUTH=off leaves the API open, e.g. for local development)
+	if envString("API_AUTH", "on") != "off" {
+		configured, err := apikeys.ParseConfig(os.Getenv("API_KEYS"))
+		if err != nil {
+			log.Fatalf("API_KEYS: %v", err)
+		}
+		limits := apikeys.Limits{
+			RateLimit:  envFloat("API_RATE_LIMIT", apikeys.DefaultLimits.RateLimit),
+			Burst:      envInt("API_BURST", apikeys.DefaultLimits.Burst),
+			DailyQuota: envInt("API_DAILY_QUOTA", apikeys.DefaultLimits.DailyQuota),
+		}
+		anon := apikeys.Limits{
+			RateLimit: envFloat("ANON_RATE_LIMIT", 1),
+			Burst:     envInt("ANON_BURST", 5),
+		}
+		if err := setupAPIKeys(db, limits, anon, configured, os.Getenv("API_ADMIN_KEY")); err != nil {
+			log.Fatalf("api keys: %v", err)
+		}
+		if len(apiKeys.List()) == 0 {
+			slog.Warn("no API keys configured: /api rejects every request (set API_ADMIN_KEY or API_KEYS, or API_AUTH=off)")
+		}
+		go apiGuard.Run(context.Background(), 10*time.Second)
+	}
+
+	// TRUSTED PROXIES (TRUSTED_PROXIES=10.0.0.0/8,... ; default none)
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trustedProxies = append(trustedProxies, p)
+		}
+	}
+
+	// PARSER RULES
+	if rulesPath = os.Getenv("PARSER_RULES_PATH"); rulesPath != "" {
+		if _, err := reloadRules(); err != nil {
+			log.Fatalf("parser rules: %v", err)
+		}
+		go reloadRulesOnSignal()
+	}
+
+	// ASSET MIRROR (ASSETS_DIR enables it; unreferenced images are deleted
+	// every ASSETS_GC_INTERVAL)
+	if dir := os.Getenv("ASSETS_DIR"); dir != "" {
+		if assetStore, err = assets.NewStore(db, dir); err != nil {
+			log.Fatalf("assets: %v", err)
+		}
+		assetStore.BaseURL = os.Getenv("ASSETS_BASE_URL")
+		go collectAssets(context.Background(), envDuration("ASSETS_GC_INTERVAL", DefaultAssetGC))
+	}
+
+	// OUTBOUND RATE LIMIT (PLAYSTORE_RATE_LIMIT requests/second)
+	rps := envFloat("PLAYSTORE_RATE_LIMIT", scraper.DefaultRateLimit)
+	scraper.SetRateLimit(rps, int(math.Max(1, math.Ceil(rps*2))))
+
+	// WATCHLIST SCHEDULER
+	scheduler := &watchlist.Scheduler{
+		Store:    watchStore,
+		Interval: envDuration("WATCH_INTERVAL", DefaultWatchInterval),
+		Jitter:   envDurationOrOff("WATCH_JITTER", DefaultWatchJitter),
+		Refresh: func(ctx context.Context, pkg string) error {
+			_, err := scrapeApp(ctx, pkg)
+			return err
+		},
+	}
+	if err := scheduler.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	go scheduler.Run(context.Background())
+
+	// BATCH JOBS (JOB_CONCURRENCY packages at once, kept for JOB_RETENTION)
+	jobManager.Concurrency = envInt("JOB_CONCURRENCY", BatchConcurrency)
+	jobManager.Retention = envDurationOrOff("JOB_RETENTION", DefaultJobRetention)
+	go jobManager.Run(context.Background())
+
+	// GRPC (GRPC_ADDR, "off" to disable) alongside the HTTP server
+	if addr := envString("GRPC_ADDR", DefaultGRPCAddr); addr != "off" {
+		go serveGRPC(addr)
+	}
+
+	newRouter(db, scheduler.Interval).Run(":8000")
+}
+
+// collectAssets deletes the mirrored images no history snapshot references,
+// now and every interval
+func collectAssets(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		refs, err := historyStore.Images()
+		if err == nil {
+			var res assets.Collected
+			if res, err = assetStore.Collect(refs, DefaultAssetGrace); err == nil && res.Removed > 0 {
+				slog.Info("assets collected", "removed", res.Removed, "freed_bytes", res.Freed, "kept", res.Kept)
+			}
+		}
+		if err != nil {
+			slog.Error("asset collection failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// openStores prepares the history, watchlist, alert and job stores in db
+func openStores(db *storage.DB) error {
+	var err error
+
+	historyStore, err = history.NewStore(db)
+	if err != nil {
+		return fmt.Errorf("history: %v", err)
+	}
+
+	watchStore, err = watchlist.NewStore(db)
+	if err != nil {
+		return fmt.Errorf("watchlist: %v", err)
+	}
+
+	alertStore, err = alerts.NewStore(db)
+	if err != nil {
+		return fmt.Errorf("alerts: %v", err)
+	}
+	alertNotifier = alerts.NewNotifier(alertStore)
+	alertEngine = alerts.NewEngine(alertStore, alertNotifier)
+
+	jobStore, err := jobs.NewStore(db)
+	if err != nil {
+		return fmt.Errorf("jobs: %v", err)
+	}
+	jobManager = jobs.NewManager(jobStore, fetchApp, BatchConcurrency)
+	return nil
+}
+
+// newRouter wires every HTML and API route; watchInterval is shown on the
+// watchlist page. Templates are loaded from templates/ in the working directory.
+func newRouter(db *storage.DB, watchInterval time.Duration) *gin.Engine {
+	r := gin.New()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES: %v", err)
+	}
+	r.Use(gin.Recovery(), middleware.RequestLog(), middleware.Metrics())
 	r.LoadHTMLGlob("templates/*")
 
+	//-----------------------------------------------------------------------
+	// PROMETHEUS METRICS
+	//-----------------------------------------------------------------------
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	//-----------------------------------------------------------------------
+	// HEALTH, READINESS AND SELF-TEST
+	//-----------------------------------------------------------------------
+	ready := health.NewChecker()
+	ready.Add("storage", func(ctx context.Context) error { return db.Ping() })
+	ready.Add("cache", checkCache)
+	ready.Add("templates", checkTemplates(r))
+
+	r.GET("/healthz", health.Liveness)
+	r.GET("/readyz", ready.Readiness())
+
+	//-----------------------------------------------------------------------
+	// MIRRORED IMAGES (ASSETS_DIR)
+	//-----------------------------------------------------------------------
+	if assetStore != nil {
+		r.GET(assets.Path+":hash", assetStore.Serve)
+	}
+
+	//-----------------------------------------------------------------------
+	// API DOCUMENTATION — OpenAPI 3 document and Swagger UI
+	//-----------------------------------------------------------------------
+	spec, err := openapi.Load()
+	if err != nil {
+		log.Fatalf("openapi: %v", err)
+	}
+	r.GET("/openapi.yaml", spec.ServeYAML)
+	r.GET("/openapi.json", spec.ServeJSON)
+	r.GET("/docs", spec.ServeUI)
+
+	// parses the bundled fixture pages: tells whether the parser still works
+	// without touching Google Play
+	r.GET("/selftest", func(c *gin.Context) {
+		report := selftest.Run()
+		status := http.StatusOK
+		if !report.OK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, report)
+	})
+
 	//-----------------------------------------------------------------------
 	// HOME PAGE
 	//-----------------------------------------------------------------------
 	r.GET("/", func(c *gin.Context) {
-		c.HTML(200, "index.html", nil)
+		c.HTML(http.StatusOK, "index.html", nil)
 	})
 
 	//-----------------------------------------------------------------------
 	// APP INFO ROUTE — security + caching + retry + scalability
 	//-----------------------------------------------------------------------
-	r.GET("/app-info", func(c *gin.Context) {
+	r.GET("/app-info", openRoute(), func(c *gin.Context) {
 
 		raw := c.Query("package")
 
 		// SECURITY
 		pkg, err := sanitizePackage(raw)
 		if err != nil {
-			output.ShowErrorPage(c, err.Error())
+			output.ShowErrorPage(c, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// CACHE CHECK
-		if app, ok := getFromCache(pkg); ok {
-			fmt.Println("CACHE HIT:", pkg)
-			output.ShowAppInfo(c, app)
+		country, err := sanitizeCountry(c.Query("country"))
+		if err != nil {
+			output.ShowErrorPage(c, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// RETRY SCRAPER (3 attempts)
-		var doc *goquery.Document
-		for retry := 1; retry <= 3; retry++ {
-			doc, err = scraper.FetchPlayStoreHTML(pkg)
-			if err == nil {
-				break
-			}
-			fmt.Println("Retry:", retry, "for", pkg)
-			time.Sleep(time.Second)
+		app, err := fetchAppIn(c.Request.Context(), pkg, country)
+		if err != nil {
+			output.ShowErrorPage(c, apiStatus(err), err.Error())
+			return
 		}
 
+		// DOWNLOAD (?format=csv|xlsx)
+		if format := c.Query("format"); output.IsExportFormat(format) {
+			output.SendExport(c, format, pkg, []output.ExportRow{{Package: pkg, App: app}})
+			return
+		}
+
+		// DISPLAY RESULT
+		output.ShowAppInfo(c, app)
+	})
+
+	//-----------------------------------------------------------------------
+	// COMPARE PAGE — /compare?packages=com.a,com.b
+	//-----------------------------------------------------------------------
+	r.GET("/compare", openRoute(), func(c *gin.Context) {
+		if c.Query("packages") == "" {
+			output.ShowComparePage(c, compare.Table{}) // empty form
+			return
+		}
+
+		pkgs, err := comparePackages(c)
 		if err != nil {
-			output.ShowErrorPage(c, "Failed to reach Google Play. Try again.")
+			output.ShowErrorPage(c, http.StatusBadRequest, err.Error())
 			return
 		}
+		if !chargePackages(c, pkgs, output.ShowErrorPage) {
+			return
+		}
+		output.ShowComparePage(c, buildComparison(c.Request.Context(), pkgs))
+	})
 
-		// PARSE APP
-		app, err := parser.ParsePlayStoreHTML(doc)
+	//-----------------------------------------------------------------------
+	// CHANGES PAGE — /changes?package=com.whatsapp[&from=&to=]
+	//-----------------------------------------------------------------------
+	r.GET("/changes", func(c *gin.Context) {
+		pkg, err := sanitizePackage(c.Query("package"))
 		if err != nil {
-			output.ShowErrorPage(c, err.Error())
+			output.ShowErrorPage(c, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// SAVE TO CACHE
-		saveToCache(pkg, app)
-		fmt.Println("CACHE SAVED:", pkg)
+		from, to, err := dateRange(c)
+		if err != nil {
+			output.ShowErrorPage(c, http.StatusBadRequest, err.Error())
+			return
+		}
 
-		// DISPLAY RESULT
-		output.ShowAppInfo(c, app)
+		snaps, err := historyStore.Changes(pkg, from, to)
+		if err != nil {
+			output.ShowErrorPage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		output.ShowChangesPage(c, pkg, from, to, snaps)
+	})
+
+	//-----------------------------------------------------------------------
+	// WATCHLIST PAGE — list, add and remove watched packages
+	//-----------------------------------------------------------------------
+	r.GET("/watchlist", func(c *gin.Context) {
+		entries, err := watchStore.List()
+		if err != nil {
+			output.ShowErrorPage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		output.ShowWatchlistPage(c, entries, watchInterval, apiGuard != nil)
+	})
+
+	r.POST("/watchlist", formRoute(), func(c *gin.Context) {
+		pkg, err := sanitizePackage(c.PostForm("package"))
+		if err != nil {
+			output.ShowErrorPage(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, err := watchStore.Add(pkg); err != nil {
+			output.ShowErrorPage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Redirect(http.StatusSeeOther, "/watchlist")
 	})
 
-	r.Run(":8000")
+	r.POST("/watchlist/remove", formRoute(), func(c *gin.Context) {
+		pkg, err := sanitizePackage(c.PostForm("package"))
+		if err != nil {
+			output.ShowErrorPage(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, err := watchStore.Remove(pkg); err != nil {
+			output.ShowErrorPage(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Redirect(http.StatusSeeOther, "/watchlist")
+	})
+
+	//-----------------------------------------------------------------------
+	// JSON API — single app and batch (?format=json|csv|xlsx)
+	//-----------------------------------------------------------------------
+	api := r.Group("/api")
+	admin := r.Group("/admin")
+	adminOnly := func(c *gin.Context) { c.Next() }
+	if apiGuard != nil {
+		api.Use(apiGuard.RequireKey())
+		admin.Use(apiGuard.RequireAdminKey())
+		adminOnly = apikeys.RequireAdmin()
+	}
+	api.GET("/app-info", apiAppInfo)
+	api.GET("/reviews/:package", apiReviews)
+	api.GET("/search", apiSearch)
+	api.GET("/batch", apiBatch)
+	api.POST("/batch", apiBatch)
+	api.GET("/batch/stream", apiBatchStream)
+	api.POST("/batch/stream", apiBatchStream)
+	api.GET("/compare", apiCompare)
+	api.GET("/history/:package", apiHistory)
+	api.GET("/history/:package/changes", apiChanges)
+	api.GET("/watchlist", apiWatchlist)
+	api.POST("/watchlist", apiWatchlistAdd)
+	api.DELETE("/watchlist/:package", apiWatchlistRemove)
+	api.GET("/alerts/rules", adminOnly, apiAlertRules)
+	api.POST("/alerts/rules", adminOnly, apiAlertRuleAdd)
+	api.DELETE("/alerts/rules/:id", adminOnly, apiAlertRuleDelete)
+	api.POST("/alerts/rules/:id/test", adminOnly, apiAlertRuleTest)
+	api.GET("/alerts/deliveries", adminOnly, apiAlertDeliveries)
+	api.GET("/parser/rules", apiParserRules)
+	api.POST("/parser/rules/reload", adminOnly, apiParserRulesReload)
+
+	//-----------------------------------------------------------------------
+	// BATCH JOBS — submit, poll (or stream), download and cancel large batches
+	//-----------------------------------------------------------------------
+	api.POST("/jobs", apiJobCreate)
+	api.GET("/jobs", apiJobs)
+	api.GET("/jobs/:id", apiJob)
+	api.GET("/jobs/:id/events", apiJobEvents)
+	api.GET("/jobs/:id/results", apiJobResults)
+	api.POST("/jobs/:id/cancel", apiJobCancel)
+
+	//-----------------------------------------------------------------------
+	// GRAPHQL — apps, reviews and history in one round trip
+	//-----------------------------------------------------------------------
+	schema, err := gql.NewSchema(gql.Sources{
+		App:         fetchApp,
+		Reviews:     fetchReviews,
+		History:     historyStore,
+		Validate:    sanitizePackage,
+		Charge:      chargeContext,
+		MaxApps:     MaxBatchSize,
+		Concurrency: BatchConcurrency,
+	})
+	if err != nil {
+		log.Fatalf("graphql schema: %v", err)
+	}
+	api.GET("/graphql", gql.Handler(schema))
+	api.POST("/graphql", gql.Handler(schema))
+
+	//-----------------------------------------------------------------------
+	// ADMIN — API keys and their usage (admin key required)
+	//-----------------------------------------------------------------------
+	if apiKeys != nil {
+		admin.GET("/keys", apiAdminKeys)
+		admin.POST("/keys", apiAdminKeyCreate)
+		admin.GET("/keys/:id", apiAdminKey)
+		admin.DELETE("/keys/:id", apiAdminKeyRevoke)
+	}
+
+	return r
 }
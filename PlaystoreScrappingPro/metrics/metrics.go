@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// UPSTREAM — requests to Google Play
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	UpstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "playstore_upstream_request_duration_seconds",
+		Help:    "Latency of requests to Google Play by status code (\"error\" = no response).",
+		Buckets: []float64{0.1, 0.25, 0.5, 0.75, 1, 1.5, 2, 3, 5},
+	}, []string{"status"})
+
+	UpstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "playstore_upstream_requests_total",
+		Help: "Requests to Google Play by status code (\"error\" = no response).",
+	}, []string{"status"})
+
+	ScrapeRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "playstore_scrape_retries_total",
+		Help: "Scrape attempts that failed and were retried.",
+	})
+)
+
+// ObserveUpstream records one request to Google Play (status 0 = transport error)
+func ObserveUpstream(status int, d time.Duration) {
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	UpstreamDuration.WithLabelValues(label).Observe(d.Seconds())
+	UpstreamRequests.WithLabelValues(label).Inc()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PARSER — fields that fell back to a placeholder
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	ParseFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "playstore_parse_field_failures_total",
+		Help: "Parsed pages where a field could not be extracted, by field.",
+	}, []string{"field"})
+
+	Parses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "playstore_parses_total",
+		Help: "Detail pages run through the parser.",
+	})
+)
+
+// ObserveParse counts a parse and every field that fell back to a placeholder;
+// a nil app means the page had no title at all
+func ObserveParse(app *parser.App) {
+	Parses.Inc()
+	if app == nil {
+		ParseFailures.WithLabelValues("title").Inc()
+		return
+	}
+	for _, field := range parser.MissingFields(app) {
+		ParseFailures.WithLabelValues(field).Inc()
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CACHE
+///////////////////////////////////////////////////////////////////////////////
+
+var (
+	CacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "playstore_cache_hits_total",
+		Help: "App lookups served from the cache.",
+	})
+
+	CacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "playstore_cache_misses_total",
+		Help: "App lookups not found in the cache (or expired).",
+	})
+
+	CacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "playstore_cache_evictions_total",
+		Help: "Cache entries removed because they expired.",
+	})
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// HTTP — inbound handler latency per route
+///////////////////////////////////////////////////////////////////////////////
+
+var HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_request_duration_seconds",
+	Help:    "Latency of inbound HTTP requests by route, method and status code.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"route", "method", "status"})
+
+// Handler serves the Prometheus text exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
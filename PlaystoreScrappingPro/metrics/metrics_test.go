@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// the collectors are global, so the tests compare deltas
+func TestObserve(t *testing.T) {
+	ok, failed := testutil.ToFloat64(UpstreamRequests.WithLabelValues("200")), testutil.ToFloat64(UpstreamRequests.WithLabelValues("error"))
+	ObserveUpstream(200, 300*time.Millisecond)
+	ObserveUpstream(0, time.Second)
+	if got := testutil.ToFloat64(UpstreamRequests.WithLabelValues("200")) - ok; got != 1 {
+		t.Errorf("upstream 200 = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(UpstreamRequests.WithLabelValues("error")) - failed; got != 1 {
+		t.Errorf("upstream transport errors = %v, want 1", got)
+	}
+
+	parses := testutil.ToFloat64(Parses)
+	before := make(map[string]float64)
+	for _, field := range []string{"title", "version", "icon"} {
+		before[field] = testutil.ToFloat64(ParseFailures.WithLabelValues(field))
+	}
+	ObserveParse(nil)
+	ObserveParse(&parser.App{Icon: "i.png", Developer: "Dev", Category: "Tools", Rating: "4.1", RatingCount: "10",
+		Installs: "1K+", LastUpdated: "Mar 1, 2026", CurrentVersion: "N.A", AndroidVersion: "8.0", Description: "d",
+		Screenshots: []string{"a.png"}})
+	if got := testutil.ToFloat64(Parses) - parses; got != 2 {
+		t.Errorf("parses = %v, want 2", got)
+	}
+	for field, want := range map[string]float64{"title": 1, "version": 1, "icon": 0} {
+		if got := testutil.ToFloat64(ParseFailures.WithLabelValues(field)) - before[field]; got != want {
+			t.Errorf("parse failures for %s = %v, want %v", field, got, want)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	HTTPDuration.WithLabelValues("/api/app-info", "GET", "200").Observe(0.05)
+
+	names := []string{
+		"playstore_upstream_request_duration_seconds",
+		"playstore_upstream_requests_total",
+		"playstore_scrape_retries_total",
+		"playstore_parse_field_failures_total",
+		"playstore_parses_total",
+		"playstore_cache_hits_total",
+		"playstore_cache_misses_total",
+		"playstore_cache_evictions_total",
+		"http_request_duration_seconds",
+	}
+	for _, name := range names {
+		if n, err := testutil.GatherAndCount(prometheus.DefaultGatherer, name); err != nil || n == 0 {
+			t.Errorf("%s: %d series registered (%v)", name, n, err)
+		}
+	}
+
+	problems, err := testutil.GatherAndLint(prometheus.DefaultGatherer, names...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Errorf("lint %s: %s", p.Metric, p.Text)
+	}
+
+	// /metrics exposes the labelled series
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("/metrics: status = %d", w.Code)
+	}
+	for _, want := range []string{
+		`http_request_duration_seconds_count{method="GET",route="/api/app-info",status="200"}`,
+		`playstore_parse_field_failures_total{field=`,
+		`# TYPE playstore_upstream_requests_total counter`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("/metrics does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeYAML handles GET /openapi.yaml
+func (s *Spec) ServeYAML(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", document)
+}
+
+// ServeJSON handles GET /openapi.json
+func (s *Spec) ServeJSON(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", s.json)
+}
+
+// ServeUI handles GET /docs: Swagger UI (loaded from a CDN) showing
+// /openapi.json. "Authorize" takes an API key for "Try it out".
+func (s *Spec) ServeUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(uiPage))
+}
+
+// swaggerUI is the pinned swagger-ui-dist release the page loads
+const swaggerUI = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+const uiPage = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Play Store Scraper API</title>
+  <link rel="stylesheet" href="` + swaggerUI + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="` + swaggerUI + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "/openapi.json",
+      dom_id: "#swagger-ui",
+      persistAuthorization: true,
+    });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,354 @@
+// Package openapi publishes the OpenAPI 3 description of the JSON API
+// (openapi.yaml) and checks responses against it, so the handlers, the
+// document and the Go client (apiclient) can't drift apart unnoticed.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+//go:embed openapi.yaml
+var document []byte
+
+// YAML returns the document as written
+func YAML() []byte {
+	return document
+}
+
+// Spec is the parsed document
+type Spec struct {
+	json []byte
+	doc  map[string]interface{}
+	ops  []operation
+}
+
+// operation is one method on one path template
+type operation struct {
+	method string
+	path   string         // e.g. /api/jobs/{id}
+	match  *regexp.Regexp // the path with each {param} matching a segment
+	op     map[string]interface{}
+}
+
+var paramRe = regexp.MustCompile(`\{[^/}]+\}`)
+
+// Load parses the embedded document
+func Load() (*Spec, error) {
+	js, err := yaml.YAMLToJSON(document)
+	if err != nil {
+		return nil, fmt.Errorf("openapi.yaml: %v", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, js); err != nil {
+		return nil, fmt.Errorf("openapi.yaml: %v", err)
+	}
+	s := &Spec{json: compact.Bytes()}
+	if err := json.Unmarshal(js, &s.doc); err != nil {
+		return nil, fmt.Errorf("openapi.yaml: %v", err)
+	}
+
+	paths, _ := s.doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		methods, _ := item.(map[string]interface{})
+		// each {param} matches one segment; the rest is literal
+		pattern := regexp.QuoteMeta(paramRe.ReplaceAllString(path, "\x00"))
+		re, err := regexp.Compile("^" + strings.ReplaceAll(pattern, "\x00", "[^/]+") + "$")
+		if err != nil {
+			return nil, fmt.Errorf("openapi.yaml: path %s: %v", path, err)
+		}
+		for method, op := range methods {
+			if m, ok := op.(map[string]interface{}); ok {
+				s.ops = append(s.ops, operation{method: strings.ToUpper(method), path: path, match: re, op: m})
+			}
+		}
+	}
+	sort.Slice(s.ops, func(i, j int) bool {
+		if s.ops[i].path != s.ops[j].path {
+			return s.ops[i].path < s.ops[j].path
+		}
+		return s.ops[i].method < s.ops[j].method
+	})
+	return s, nil
+}
+
+// JSON returns the document as JSON
+func (s *Spec) JSON() []byte {
+	return s.json
+}
+
+// Operations lists every "METHOD /path/{param}" the document describes
+func (s *Spec) Operations() []string {
+	out := make([]string, len(s.ops))
+	for i, op := range s.ops {
+		out[i] = op.method + " " + op.path
+	}
+	return out
+}
+
+// RoutePath turns a gin route ("/api/jobs/:id") into an OpenAPI path
+// ("/api/jobs/{id}")
+func RoutePath(route string) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// ValidateResponse checks a response to method urlPath (a concrete path
+// such as /api/jobs/abc) against the document: the status must be listed
+// and a JSON body must match its schema.
+func (s *Spec) ValidateResponse(method, urlPath string, status int, contentType string, body []byte) error {
+	var op *operation
+	for i := range s.ops {
+		if s.ops[i].method == method && s.ops[i].match.MatchString(urlPath) {
+			op = &s.ops[i]
+			break
+		}
+	}
+	if op == nil {
+		return fmt.Errorf("%s %s is not described", method, urlPath)
+	}
+	where := fmt.Sprintf("%s %s %d", method, op.path, status)
+
+	responses, _ := op.op["responses"].(map[string]interface{})
+	resp, ok := responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("%s: status not described", where)
+	}
+	r, err := s.resolve(resp)
+	if err != nil {
+		return fmt.Errorf("%s: %v", where, err)
+	}
+
+	content, _ := r["content"].(map[string]interface{})
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	if len(content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s: unexpected %s body", where, mediaType)
+		}
+		return nil
+	}
+	media, ok := content[mediaType].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: content type %q not described", where, mediaType)
+	}
+	schema, ok := media["schema"]
+	if !ok || mediaType != "application/json" {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s: invalid JSON: %v", where, err)
+	}
+	if err := s.validate(schema, value, "$"); err != nil {
+		return fmt.Errorf("%s: %v", where, err)
+	}
+	return nil
+}
+
+// resolve follows a local $ref ("#/components/...")
+func (s *Spec) resolve(v interface{}) (map[string]interface{}, error) {
+	m, _ := v.(map[string]interface{})
+	for depth := 0; m != nil; depth++ {
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m, nil
+		}
+		if depth > 16 || !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		var cur interface{} = s.doc
+		for _, part := range strings.Split(ref[2:], "/") {
+			obj, _ := cur.(map[string]interface{})
+			cur = obj[part]
+		}
+		m, _ = cur.(map[string]interface{})
+		if m == nil {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return nil, fmt.Errorf("schema is not an object")
+}
+
+// validate checks value against the subset of JSON Schema the document
+// uses: type, nullable, enum, properties, required, additionalProperties,
+// items, allOf and format date-time. Unlike JSON Schema, an object property
+// the schema doesn't list is an error unless additionalProperties allows
+// it: a field added to a handler must be added to the document too.
+func (s *Spec) validate(schemaRef, value interface{}, at string) error {
+	schema, err := s.resolve(schemaRef)
+	if err != nil {
+		return fmt.Errorf("%s: %v", at, err)
+	}
+	if schema, err = s.merge(schema); err != nil {
+		return fmt.Errorf("%s: %v", at, err)
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || len(schema) == 0 {
+			return nil
+		}
+		if _, typed := schema["type"]; typed {
+			return fmt.Errorf("%s: null is not allowed", at)
+		}
+	}
+
+	if enum := list(schema["enum"]); enum != nil {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: want an object, got %s", at, kind(value))
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		for _, name := range list(schema["required"]) {
+			if _, ok := obj[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %q", at, name)
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := props[name]; ok {
+				if err := s.validate(prop, obj[name], at+"."+name); err != nil {
+					return err
+				}
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				if err := s.validate(extra, obj[name], at+"."+name); err != nil {
+					return err
+				}
+			case bool:
+				if !extra {
+					return fmt.Errorf("%s: unexpected property %q", at, name)
+				}
+			default:
+				return fmt.Errorf("%s: undocumented property %q", at, name)
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: want an array, got %s", at, kind(value))
+		}
+		if items, ok := schema["items"]; ok {
+			for i, v := range arr {
+				if err := s.validate(items, v, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: want a string, got %s", at, kind(value))
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s: %q is not an RFC 3339 date-time", at, str)
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: want an integer, got %v", at, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: want a number, got %s", at, kind(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: want a boolean, got %s", at, kind(value))
+		}
+	}
+	return nil
+}
+
+// merge folds the object schemas of an allOf into one, so every property
+// they list is documented for all of them
+func (s *Spec) merge(schema map[string]interface{}) (map[string]interface{}, error) {
+	all := list(schema["allOf"])
+	if all == nil {
+		return schema, nil
+	}
+
+	props := make(map[string]interface{})
+	var required []interface{}
+	for _, ref := range all {
+		sub, err := s.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		if sub, err = s.merge(sub); err != nil {
+			return nil, err
+		}
+		p, _ := sub["properties"].(map[string]interface{})
+		for name, prop := range p {
+			props[name] = prop
+		}
+		required = append(required, list(sub["required"])...)
+	}
+
+	merged := map[string]interface{}{"type": "object", "properties": props, "required": required}
+	for k, v := range schema {
+		if k != "allOf" {
+			merged[k] = v
+		}
+	}
+	return merged, nil
+}
+
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+func kind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
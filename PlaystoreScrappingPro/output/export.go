@@ -0,0 +1,290 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export formats accepted by the ?format= query parameter
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// IsExportFormat reports whether format is a spreadsheet export (csv/xlsx)
+func IsExportFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// COLUMNS — one row per app, screenshot lists flattened into a single cell
+///////////////////////////////////////////////////////////////////////////////
+
+type exportColumn struct {
+	header string
+	value  func(app *parser.App) interface{} // string or bool
+}
+
+var exportColumns = []exportColumn{
+	{"App Name/ID", func(a *parser.App) interface{} { return a.AppName }},
+	{"Title", func(a *parser.App) interface{} { return a.Title }},
+	{"Icon", func(a *parser.App) interface{} { return a.Icon }},
+	{"Developer", func(a *parser.App) interface{} { return a.Developer }},
+	{"Developer Email", func(a *parser.App) interface{} { return a.DeveloperEmail }},
+	{"Developer Website", func(a *parser.App) interface{} { return a.DeveloperWebsite }},
+	{"Category", func(a *parser.App) interface{} { return a.Category }},
+	{"Rating", func(a *parser.App) interface{} { return a.Rating }},
+	{"Total Ratings", func(a *parser.App) interface{} { return a.RatingCount }},
+	{"Rating Distribution", func(a *parser.App) interface{} { return a.RatingHistogram.String() }},
+	{"Installs", func(a *parser.App) interface{} { return a.Installs }},
+	{"Free", func(a *parser.App) interface{} { return a.Free }},
+	{"Price", func(a *parser.App) interface{} { return a.Price.String() }},
+	{"Currency", func(a *parser.App) interface{} {
+		if a.Price == nil {
+			return ""
+		}
+		return a.Price.Currency
+	}},
+	{"In-App Price Range", func(a *parser.App) interface{} { return a.InAppPrice.String() }},
+	{"Ad Supported", func(a *parser.App) interface{} { return a.AdSupported }},
+	{"In-App Purchases", func(a *parser.App) interface{} { return a.InAppPurchase }},
+	{"Last Updated", func(a *parser.App) interface{} { return a.LastUpdated }},
+	{"Current Version", func(a *parser.App) interface{} { return a.CurrentVersion }},
+	{"Android Version", func(a *parser.App) interface{} { return a.AndroidVersion }},
+	{"Short Description", func(a *parser.App) interface{} { return a.ShortDesc }},
+	{"Full Description", func(a *parser.App) interface{} { return a.Description }},
+	{"Screenshot Count", func(a *parser.App) interface{} { return strconv.Itoa(len(a.Screenshots)) }},
+	{"Screenshots", func(a *parser.App) interface{} { return strings.Join(a.Screenshots, " | ") }},
+	{"Tablet Screenshots", func(a *parser.App) interface{} { return strings.Join(a.TabletScreenshots, " | ") }},
+	{"Feature Graphic", func(a *parser.App) interface{} { return a.FeatureGraphic }},
+	{"Promo Video", func(a *parser.App) interface{} { return a.Video }},
+}
+
+// errorColumn is the last column, filled in for packages that failed
+const errorColumn = "Error"
+
+// ExportRow is one spreadsheet row: a scraped app, or the package that could
+// not be fetched and why
+type ExportRow struct {
+	Package string
+	App     *parser.App
+	Error   string
+}
+
+func exportHeader() []string {
+	header := make([]string, 0, len(exportColumns)+1)
+	for _, col := range exportColumns {
+		header = append(header, col.header)
+	}
+	return append(header, errorColumn)
+}
+
+// exportCells returns a row's values in column order; a failed package only
+// fills the first (App Name/ID) and Error columns
+func exportCells(r ExportRow) []interface{} {
+	cells := make([]interface{}, 0, len(exportColumns)+1)
+	for _, col := range exportColumns {
+		switch {
+		case r.App != nil:
+			cells = append(cells, col.value(r.App))
+		case len(cells) == 0:
+			cells = append(cells, r.Package)
+		default:
+			cells = append(cells, "")
+		}
+	}
+	return append(cells, r.Error)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CSV
+///////////////////////////////////////////////////////////////////////////////
+
+// WriteCSV writes a header row followed by one line per row
+func WriteCSV(w io.Writer, rows []ExportRow) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(exportHeader()); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		cells := exportCells(r)
+		row := make([]string, len(cells))
+		for i, cell := range cells {
+			switch v := cell.(type) {
+			case bool:
+				row[i] = strings.ToUpper(strconv.FormatBool(v))
+			case string:
+				row[i] = csvSafe(v)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvSafe stops scraped text from being evaluated as a spreadsheet formula
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// XLSX — minimal SpreadsheetML package (inline strings, no shared table)
+///////////////////////////////////////////////////////////////////////////////
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Apps" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+// WriteXLSX writes a single-sheet workbook with a header row and the rows
+func WriteXLSX(w io.Writer, rows []ExportRow) error {
+	zw := zip.NewWriter(w)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(rows)},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func xlsxSheet(rows []ExportRow) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	// header row
+	b.WriteString(`<row r="1">`)
+	for i, header := range exportHeader() {
+		xlsxCell(&b, xlsxRef(i, 1), header)
+	}
+	b.WriteString(`</row>`)
+
+	for n, r := range rows {
+		rowNum := n + 2
+		fmt.Fprintf(&b, `<row r="%d">`, rowNum)
+		for i, cell := range exportCells(r) {
+			xlsxCell(&b, xlsxRef(i, rowNum), cell)
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func xlsxCell(b *strings.Builder, ref string, value interface{}) {
+	switch v := value.(type) {
+	case bool:
+		n := 0
+		if v {
+			n = 1
+		}
+		fmt.Fprintf(b, `<c r="%s" t="b"><v>%d</v></c>`, ref, n)
+	case string:
+		var esc bytes.Buffer
+		xml.EscapeText(&esc, []byte(v))
+		fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, esc.String())
+	}
+}
+
+// xlsxRef converts a zero-based column and a one-based row to "A1" notation
+func xlsxRef(col, row int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name + strconv.Itoa(row)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// HTTP — send an export as a file download
+///////////////////////////////////////////////////////////////////////////////
+
+// SendExport writes rows as a csv or xlsx attachment named <name>.<format>
+func SendExport(c *gin.Context, format, name string, rows []ExportRow) {
+	var buf bytes.Buffer
+	var err error
+	contentType := ""
+
+	switch format {
+	case FormatCSV:
+		contentType = "text/csv; charset=utf-8"
+		err = WriteCSV(&buf, rows)
+	case FormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = WriteXLSX(&buf, rows)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported export format %q (use csv or xlsx)", format)})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// exportLink returns the current URL with ?format= set, used for download links
+func exportLink(c *gin.Context, format string) string {
+	u := *c.Request.URL
+	q := u.Query()
+	q.Set("format", format)
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
+}
@@ -1,21 +1,64 @@
 package output
 
 import (
-	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/assets"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/compare"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/diff"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/history"
 	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/watchlist"
 
 	"github.com/gin-gonic/gin"
 )
 
-// ShowErrorPage displays an error message
-func ShowErrorPage(c *gin.Context, message string) {
-	html := fmt.Sprintf(`
-		<h2 style="color:red;">%s</h2>
-		<a href="/">⬅ Go Back</a>
-	`, message)
-	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+// Pages are html/template files in templates/ (loaded by main via LoadHTMLGlob);
+// every scraped string is escaped by html/template on the way out.
+
+type errorView struct {
+	Title   string
+	Message string
+}
+
+type appView struct {
+	Title       string
+	Package     string
+	App         *parser.App
+	Icon        string
+	Screenshots []string
+	Tablet      []string // tablet screenshots
+	Feature     string   // feature graphic
+	Video       string
+	Rating      string
+	RatingCount string
+	Histogram   []histogramBarView
+	CSVLink     string
+	XLSXLink    string
+	Diagnostics *parser.Diagnostics // only with ?debug=1
+	DebugLink   string
+}
+
+// histogramBarView is one bar of the rating distribution
+type histogramBarView struct {
+	Stars   int
+	Percent string
+	Count   string // "" when the page didn't show it
+	Width   int    // percent of the longest bar
+}
+
+// ShowErrorPage displays an error message with the given status code
+func ShowErrorPage(c *gin.Context, status int, message string) {
+	c.HTML(status, "error.html", errorView{
+		Title:   "Error",
+		Message: message,
+	})
 }
 
 // ShowAppInfo displays full Play Store info
@@ -30,46 +73,228 @@ func ShowAppInfo(c *gin.Context, app *parser.App) {
 		ratingCount = app.RatingCount
 	}
 
-	//Build screenshot gallery
-	screensHTML := ""
-	if len(app.Screenshots) > 0 {
-		for _, img := range app.Screenshots {
-			screensHTML += fmt.Sprintf(`<img src="%s" width="160" style="border-radius:10px;margin:5px;box-shadow:0 0 5px rgba(0,0,0,0.2);">`, img)
+	// only http(s) URLs make it into src and href attributes
+	screens := safeURLs(app.Screenshots)
+
+	var diagnostics *parser.Diagnostics
+	if DebugRequested(c) {
+		diagnostics = app.Diagnostics
+	}
+
+	c.HTML(http.StatusOK, "result.html", appView{
+		Title:       app.Title,
+		Package:     c.Query("package"),
+		App:         app,
+		Icon:        SafeURL(app.Icon),
+		Screenshots: screens,
+		Tablet:      safeURLs(app.TabletScreenshots),
+		Feature:     SafeURL(app.FeatureGraphic),
+		Video:       SafeURL(app.Video),
+		Rating:      rating,
+		RatingCount: ratingCount,
+		Histogram:   histogramBars(app.RatingHistogram),
+		CSVLink:     exportLink(c, FormatCSV),
+		XLSXLink:    exportLink(c, FormatXLSX),
+		Diagnostics: diagnostics,
+		DebugLink:   debugLink(c),
+	})
+}
+
+// histogramBars scales the histogram so the most common rating fills its row
+func histogramBars(h parser.Histogram) []histogramBarView {
+	longest := 0.0
+	for _, b := range h {
+		longest = max(longest, b.Percent)
+	}
+
+	var out []histogramBarView
+	for _, b := range h {
+		bar := histogramBarView{Stars: b.Stars, Percent: strconv.FormatFloat(b.Percent, 'f', -1, 64) + "%"}
+		if b.Count > 0 {
+			bar.Count = strconv.FormatInt(b.Count, 10)
+		}
+		if longest > 0 {
+			bar.Width = int(math.Round(b.Percent / longest * 100))
+		}
+		out = append(out, bar)
+	}
+	return out
+}
+
+// DebugRequested reports whether ?debug=1 asks for extraction diagnostics
+func DebugRequested(c *gin.Context) bool {
+	debug, _ := strconv.ParseBool(c.Query("debug"))
+	return debug
+}
+
+func debugLink(c *gin.Context) string {
+	u := *c.Request.URL
+	q := u.Query()
+	q.Set("debug", "1")
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
+}
+
+// safeURLs keeps the absolute http(s) URLs of urls
+func safeURLs(urls []string) []string {
+	out := make([]string, 0, len(urls))
+	for _, raw := range urls {
+		if u := SafeURL(raw); u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// SafeURL returns raw if it is an absolute http(s) URL or a mirrored image
+// on this server (under assets.Path), otherwise ""
+func SafeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		// browsers read "\" as "/", so "/\host" is another site and
+		// "/assets/..\admin" climbs out of the assets
+		if u.Scheme != "" || u.Host != "" || strings.Contains(raw, `\`) {
+			return ""
+		}
+		if !strings.HasPrefix(path.Clean(u.Path), assets.Path) {
+			return ""
+		}
+		return u.String()
+	}
+	if u.Host == "" {
+		return ""
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return ""
+	}
+	return u.String()
+}
+
+type compareColumnView struct {
+	Package string
+	Title   string
+	Icon    string
+	Error   string
+}
+
+type compareView struct {
+	Title   string
+	Query   string
+	Columns []compareColumnView
+	Rows    []compare.Row
+}
+
+// ShowComparePage displays apps side by side with the best value per row highlighted
+func ShowComparePage(c *gin.Context, table compare.Table) {
+	view := compareView{
+		Title: "Compare",
+		Rows:  table.Rows,
+	}
+
+	pkgs := make([]string, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		cv := compareColumnView{Package: col.Package, Error: col.Error}
+		if col.App != nil {
+			cv.Title = col.App.Title
+			cv.Icon = SafeURL(col.App.Icon)
 		}
-	} else {
-		screensHTML = `<p>No screenshots available</p>`
+		view.Columns = append(view.Columns, cv)
+		pkgs = append(pkgs, col.Package)
 	}
+	view.Query = strings.Join(pkgs, ",")
+
+	c.HTML(http.StatusOK, "compare.html", view)
+}
+
+type changeView struct {
+	Label   string
+	Old     string
+	New     string
+	Delta   string
+	Words   []diff.Segment
+	Added   []string
+	Removed []string
+}
+
+type snapshotView struct {
+	Time    string
+	Changes []changeView
+}
+
+type changesView struct {
+	Title     string
+	Package   string
+	From      string
+	To        string
+	Snapshots []snapshotView
+}
+
+// wordDiffFields are shown as an inline word diff instead of before/after
+var wordDiffFields = map[string]bool{
+	"summary":     true,
+	"description": true,
+}
+
+// ShowChangesPage displays the recorded changes of a package, newest first
+func ShowChangesPage(c *gin.Context, pkg string, from, to time.Time, snaps []history.Snapshot) {
+	view := changesView{
+		Title:   pkg + " changes",
+		Package: pkg,
+		From:    from.Format("2006-01-02"),
+		To:      to.Add(-time.Nanosecond).Format("2006-01-02"), // to is exclusive
+	}
+
+	for i := len(snaps) - 1; i >= 0; i-- {
+		sv := snapshotView{Time: snaps[i].Timestamp.Format("Jan 2, 2006 15:04 MST")}
+
+		for _, ch := range snaps[i].Changes {
+			cv := changeView{Label: ch.Label, Old: ch.Old, New: ch.New}
+			if ch.Delta != 0 {
+				cv.Delta = strconv.FormatFloat(ch.Delta, 'f', -1, 64)
+				if ch.Delta > 0 {
+					cv.Delta = "+" + cv.Delta
+				}
+			}
+			if wordDiffFields[ch.Field] {
+				cv.Words = diff.Words(ch.Old, ch.New)
+			}
+			for _, u := range ch.Added {
+				if u = SafeURL(u); u != "" {
+					cv.Added = append(cv.Added, u)
+				}
+			}
+			for _, u := range ch.Removed {
+				if u = SafeURL(u); u != "" {
+					cv.Removed = append(cv.Removed, u)
+				}
+			}
+			sv.Changes = append(sv.Changes, cv)
+		}
+
+		view.Snapshots = append(view.Snapshots, sv)
+	}
+
+	c.HTML(http.StatusOK, "changes.html", view)
+}
+
+type watchlistView struct {
+	Title       string
+	Interval    time.Duration
+	Entries     []watchlist.Entry
+	KeyRequired bool // the forms post an API key
+}
 
-	html := fmt.Sprintf(`
-		<h2>Play Store App Info</h2>
-		<div style="display:flex;align-items:center;gap:15px;margin-bottom:10px;">
-			<img src="%s" alt="App Icon" width="96" height="96" style="border-radius:16px;box-shadow:0 0 6px rgba(0,0,0,0.2);">
-			<h3 style="margin:0;">%s</h3>
-		</div>
-		<pre>
-App Name/ID: %s
-Developer: %s
-Developer Email: %s
-Developer Website: %s
-Category: %s
-Rating: %s
-Total Ratings: %s
-Installs: %s
-Free: %t
-Ad Supported: %t
-In-App Purchases: %t
-Last Updated: %s
-Current Version: %s
-Android Version: %s
-Short Description: %s
-Full Description: %s
-		</pre>
-		<h3>Screenshots:</h3>
-		<div>%s</div>
-		<br><a href="/">⬅ Go Back</a>
-	`, app.Icon, app.Title, app.AppName, app.Developer, app.DeveloperEmail, app.DeveloperWebsite,
-		app.Category, rating, ratingCount, app.Installs, app.Free, app.AdSupported, app.InAppPurchase,
-		app.LastUpdated, app.CurrentVersion, app.AndroidVersion, app.ShortDesc, app.Description, screensHTML)
-
-	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+// ShowWatchlistPage lists watched packages with add/remove forms; with
+// keyRequired they ask for an API key
+func ShowWatchlistPage(c *gin.Context, entries []watchlist.Entry, interval time.Duration, keyRequired bool) {
+	c.HTML(http.StatusOK, "watchlist.html", watchlistView{
+		Title:       "Watchlist",
+		Interval:    interval,
+		Entries:     entries,
+		KeyRequired: keyRequired,
+	})
 }
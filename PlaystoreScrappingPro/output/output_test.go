@@ -0,0 +1,28 @@
+package output
+
+import "testing"
+
+func TestSafeURL(t *testing.T) {
+	for _, tt := range []struct {
+		raw, want string
+	}{
+		{"https://play-lh.googleusercontent.com/icon.png", "https://play-lh.googleusercontent.com/icon.png"},
+		{" http://example.com/a.png ", "http://example.com/a.png"},
+		{"/assets/0123abcd", "/assets/0123abcd"},
+		{"javascript:alert(1)", ""},
+		{"data:image/png;base64,AAAA", ""},
+		{"//evil.example/x.png", ""},
+		{`/\evil.example/x.png`, ""},
+		{"/admin/keys", ""},
+		{"/api/app-info?package=x", ""},
+		{"/assets/../admin/keys", ""},
+		{"/assets/%2e%2e/admin/keys", ""},
+		{`/assets/..\admin`, ""},
+		{"/assetsX/a", ""},
+		{"icon.png", ""},
+	} {
+		if got := SafeURL(tt.raw); got != tt.want {
+			t.Errorf("SafeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
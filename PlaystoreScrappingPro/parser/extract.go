@@ -0,0 +1,378 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Parse extracts app info from doc by running every field's strategies in
+// order until one yields a value
+func (r *Rules) Parse(doc *goquery.Document) (*App, error) {
+	app := &App{}
+	tr := newTrace()
+	p := &page{doc: doc, rules: r}
+	values := make(map[string]string, len(r.Fields))
+
+	for i := range r.Fields {
+		f := &r.Fields[i]
+		for j := range f.Strategies {
+			s := &f.Strategies[j]
+
+			var v string
+			if s.Kind == KindImages {
+				list := p.images(s)
+				setList(app, f.Field, list)
+				v = strings.Join(list, "\n")
+			} else {
+				v = p.extract(s, values)
+				if fieldKinds[f.Field] && s.match != nil && v != "" {
+					v = strconv.FormatBool(s.match.MatchString(v))
+				}
+			}
+
+			tr.record(f.Field, s.Source, v)
+			if v != "" {
+				values[f.Field] = v
+				break
+			}
+		}
+		setField(app, f.Field, values[f.Field])
+	}
+
+	if app.Title == "" {
+		return nil, fmt.Errorf("app not found on Play Store")
+	}
+
+	app.RatingHistogram = p.histogram()
+	tr.record("ratingHistogram", SourceSelector, app.RatingHistogram.String())
+
+	app.Price, app.InAppPrice = buildPrices(values)
+	if app.Price != nil {
+		app.Free = app.Price.Amount == 0
+	}
+	if app.InAppPrice != nil {
+		app.InAppPurchase = true
+	}
+
+	app.Diagnostics = tr.diagnostics(app)
+	return app, nil
+}
+
+// setField stores a string value in the App field with the given json name
+// (lists are set by setList, the raw price fields read by buildPrices)
+func setField(app *App, field, v string) {
+	switch field {
+	case "appName":
+		app.AppName = v
+	case "title":
+		app.Title = v
+	case "icon":
+		app.Icon = v
+	case "developer":
+		app.Developer = v
+	case "developerEmail":
+		app.DeveloperEmail = v
+	case "developerWebsite":
+		app.DeveloperWebsite = v
+	case "genre":
+		app.Category = v
+	case "rating":
+		app.Rating = v
+	case "ratingCount":
+		app.RatingCount = v
+	case "installs":
+		app.Installs = v
+	case "free":
+		app.Free = v == "true"
+	case "adSupported":
+		app.AdSupported = v == "true"
+	case "InAppPurchase":
+		app.InAppPurchase = v == "true"
+	case "updated":
+		app.LastUpdated = v
+	case "version":
+		app.CurrentVersion = v
+	case "androidVersion":
+		app.AndroidVersion = v
+	case "summary":
+		app.ShortDesc = v
+	case "description":
+		app.Description = v
+	case "featureGraphic":
+		app.FeatureGraphic = v
+	case "video":
+		app.Video = v
+	}
+}
+
+// setList stores the result of an images strategy
+func setList(app *App, field string, list []string) {
+	switch field {
+	case "screenshots":
+		app.Screenshots = list
+	case "tabletScreenshots":
+		app.TabletScreenshots = list
+	}
+}
+
+// page holds one document plus what several strategies share, computed on
+// first use
+type page struct {
+	doc   *goquery.Document
+	rules *Rules
+
+	jsonld  []map[string]interface{}
+	details []detail
+	text    *string
+
+	jsonldDone, detailsDone bool
+}
+
+type detail struct {
+	label string // lower case
+	value string
+}
+
+func (p *page) extract(s *Strategy, values map[string]string) string {
+	switch s.Kind {
+	case KindJSONLD:
+		return p.jsonldValue(s)
+	case KindSelector:
+		return s.selectFrom(p.doc.Selection)
+	case KindDetails:
+		return p.detailValue(s)
+	case KindScript:
+		return p.scriptValue(s)
+	case KindPageRegex:
+		text := p.pageText()
+		if s.Lowercase {
+			text = strings.ToLower(text)
+		}
+		return s.find(text)
+	case KindPageContains:
+		lower := strings.ToLower(p.pageText())
+		for _, k := range s.Keywords {
+			if strings.Contains(lower, strings.ToLower(k)) {
+				return "true"
+			}
+		}
+		return "false"
+	case KindField:
+		return values[s.Field]
+	case KindDefault:
+		return s.Value
+	}
+	return ""
+}
+
+func (p *page) pageText() string {
+	if p.text == nil {
+		t := p.doc.Text()
+		p.text = &t
+	}
+	return *p.text
+}
+
+// jsonldValue looks Path up in each structured-data block of the right type
+func (p *page) jsonldValue(s *Strategy) string {
+	if !p.jsonldDone {
+		p.jsonldDone = true
+		p.doc.Find("script[type='application/ld+json']").Each(func(i int, sel *goquery.Selection) {
+			text := strings.TrimSpace(sel.Text())
+			if p.rules.JSONLDType != "" && !strings.Contains(text, p.rules.JSONLDType) {
+				return
+			}
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(text), &data); err == nil {
+				p.jsonld = append(p.jsonld, data)
+			}
+		})
+	}
+
+	for _, data := range p.jsonld {
+		var v interface{} = data
+		for _, key := range strings.Split(s.Path, ".") {
+			switch node := v.(type) {
+			case map[string]interface{}:
+				v = node[key]
+			case []interface{}:
+				// a numeric key indexes a list, e.g. offers.0.price
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(node) {
+					v = nil
+				} else {
+					v = node[i]
+				}
+			default:
+				v = nil
+			}
+			if v == nil {
+				break
+			}
+		}
+		if v == nil {
+			continue
+		}
+		if out := s.clean(fmt.Sprint(v)); out != "" {
+			return out
+		}
+	}
+	return ""
+}
+
+// detailValue returns the value of the first details block whose label
+// contains one of the keywords
+func (p *page) detailValue(s *Strategy) string {
+	if !p.detailsDone {
+		p.detailsDone = true
+		d := p.rules.Details
+		if d.Block != "" {
+			p.doc.Find(d.Block).Each(func(i int, block *goquery.Selection) {
+				p.details = append(p.details, detail{
+					label: strings.ToLower(firstOf(d.Label, block)),
+					value: firstOf(d.Value, block),
+				})
+			})
+		}
+	}
+
+	for _, d := range p.details {
+		if d.value == "" {
+			continue
+		}
+		for _, k := range s.Keywords {
+			if strings.Contains(d.label, strings.ToLower(k)) {
+				return s.clean(d.value)
+			}
+		}
+	}
+	return ""
+}
+
+// scriptValue runs Regex over <script> bodies that mention a keyword
+func (p *page) scriptValue(s *Strategy) string {
+	found := ""
+	p.doc.Find("script").EachWithBreak(func(i int, sel *goquery.Selection) bool {
+		t := sel.Text()
+		lower := strings.ToLower(t)
+		for _, k := range s.Keywords {
+			if strings.Contains(lower, strings.ToLower(k)) {
+				found = s.find(t)
+				break
+			}
+		}
+		return found == ""
+	})
+	return found
+}
+
+// images collects the distinct image URLs containing a keyword, in page
+// order (at most Limit when it is set)
+func (p *page) images(s *Strategy) []string {
+	var out []string
+	p.doc.Find(s.Selector).EachWithBreak(func(i int, sel *goquery.Selection) bool {
+		if s.Limit > 0 && len(out) >= s.Limit {
+			return false
+		}
+
+		src := sel.AttrOr("src", "")
+		if src == "" {
+			src = sel.AttrOr("data-src", "")
+		}
+		if src == "" {
+			// srcset: take the first url
+			if fields := strings.Fields(sel.AttrOr("srcset", "")); len(fields) > 0 {
+				src = strings.TrimSuffix(fields[0], ",")
+			}
+		}
+		if src == "" || !containsAny(src, s.Keywords) || containsAny(src, s.Exclude) {
+			return true
+		}
+
+		for _, ex := range out {
+			if ex == src {
+				return true
+			}
+		}
+		out = append(out, src)
+		return true
+	})
+	return out
+}
+
+// firstOf returns the first value any of the selector strategies finds in root
+func firstOf(strategies []Strategy, root *goquery.Selection) string {
+	for i := range strategies {
+		if v := strategies[i].selectFrom(root); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// selectFrom returns the first non-empty value among the elements matching
+// Selector (the last one first with Last)
+func (s *Strategy) selectFrom(root *goquery.Selection) string {
+	nodes := root.Find(s.Selector)
+	n := nodes.Length()
+	for i := 0; i < n; i++ {
+		idx := i
+		if s.Last {
+			idx = n - 1 - i
+		}
+		el := nodes.Eq(idx)
+
+		v := ""
+		if s.Attr != "" {
+			v = el.AttrOr(s.Attr, "")
+		} else {
+			v = el.Text()
+		}
+		if v = s.clean(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean trims v and applies Collapse, Regex and Exclude
+func (s *Strategy) clean(v string) string {
+	v = strings.TrimSpace(v)
+	if s.Collapse {
+		v = strings.Join(strings.Fields(v), " ")
+	}
+	if s.re != nil {
+		v = s.find(v)
+	}
+	if containsAny(v, s.Exclude) {
+		return ""
+	}
+	return v
+}
+
+// find returns Regex's first non-empty capture group, or the whole match
+func (s *Strategy) find(text string) string {
+	m := s.re.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+	for _, g := range m[1:] {
+		if g != "" {
+			return strings.TrimSpace(g)
+		}
+	}
+	return strings.TrimSpace(m[0])
+}
+
+func containsAny(v string, needles []string) bool {
+	for _, n := range needles {
+		if strings.Contains(v, n) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+// Package fixtures is the one set of recorded Play Store detail pages. Each
+// <name>.html has a <name>.golden next to it: the App ParsePlayStoreHTML
+// returns, as indented JSON, or {"error": "..."} when parsing must fail.
+//
+// The parser golden tests read these files from disk (and rewrite the goldens
+// with -update); the fake store serves some of them as detail pages and the
+// /selftest endpoint checks the loaded rules against all of them.
+package fixtures
+
+import (
+	"embed"
+	"strings"
+)
+
+// FS holds every <name>.html and <name>.golden
+//
+//go:embed *.html *.golden
+var FS embed.FS
+
+// Names returns the fixture names (the pages without .html), sorted
+func Names() []string {
+	entries, _ := FS.ReadDir(".")
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".html"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Page returns the recorded page of a fixture
+func Page(name string) ([]byte, error) {
+	return FS.ReadFile(name + ".html")
+}
+
+// Golden returns the expected parse result of a fixture
+func Golden(name string) ([]byte, error) {
+	return FS.ReadFile(name + ".golden")
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Every fixtures/<name>.html page (see cmd/record-fixtures) is parsed and
+// compared with fixtures/<name>.golden: the App as indented JSON, or
+// {"error": "..."} when parsing must fail. After an intended parser or rules
+// change, regenerate them and review the diff:
+//
+//	go test ./parser -run TestParseGolden -update
+var update = flag.Bool("update", false, "rewrite fixtures/*.golden from the current parser output")
+
+func TestParseGolden(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("fixtures", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no pages in fixtures")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		golden := filepath.Join("fixtures", name+".golden")
+
+		t.Run(name, func(t *testing.T) {
+			got := parseFixture(t, page)
+
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				reportDiff(t, want, got)
+			}
+		})
+	}
+}
+
+// parseFixture returns the golden representation of a fixture's parse result
+func parseFixture(t *testing.T, path string) []byte {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v interface{}
+	app, err := ParsePlayStoreHTML(doc)
+	if err != nil {
+		v = map[string]string{"error": err.Error()}
+	} else {
+		v = app
+	}
+
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(out, '\n')
+}
+
+// reportDiff lists the top-level fields that differ between two goldens
+func reportDiff(t *testing.T, want, got []byte) {
+	t.Helper()
+
+	var w, g map[string]json.RawMessage
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("golden is not valid JSON: %v", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make(map[string]bool)
+	for k := range w {
+		keys[k] = true
+	}
+	for k := range g {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		if !bytes.Equal(w[k], g[k]) {
+			t.Errorf("%s:\n  want %s\n  got  %s", k, orMissing(w[k]), orMissing(g[k]))
+		}
+	}
+	if !t.Failed() {
+		t.Errorf("output differs from golden in formatting only; run with -update")
+	}
+}
+
+func orMissing(v json.RawMessage) string {
+	if v == nil {
+		return "(missing)"
+	}
+	return string(v)
+}
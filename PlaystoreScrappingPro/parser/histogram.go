@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// RatingBar is one row of the star rating histogram
+type RatingBar struct {
+	Stars   int     `json:"stars"`           // 1 to 5
+	Percent float64 `json:"percent"`         // share of all ratings, 0 to 100
+	Count   int64   `json:"count,omitempty"` // only when the page shows it
+}
+
+// Histogram is the rating distribution, 5 stars first. It is empty when the
+// page has none (e.g. an app without ratings).
+type Histogram []RatingBar
+
+// String formats h as "5★ 72% · 4★ 12% · ..."
+func (h Histogram) String() string {
+	parts := make([]string, len(h))
+	for i, b := range h {
+		parts[i] = strconv.Itoa(b.Stars) + "★ " + strconv.FormatFloat(b.Percent, 'f', -1, 64) + "%"
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Average returns the mean star rating h describes, false when it is empty
+func (h Histogram) Average() (float64, bool) {
+	sum, total := 0.0, 0.0
+	for _, b := range h {
+		sum += float64(b.Stars) * b.Percent
+		total += b.Percent
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return math.Round(sum/total*100) / 100, true
+}
+
+// Share returns the percentage of ratings with the given number of stars
+func (h Histogram) Share(stars int) float64 {
+	for _, b := range h {
+		if b.Stars == stars {
+			return b.Percent
+		}
+	}
+	return 0
+}
+
+// histogram reads the rows described by the rules' HistogramRule. Percent
+// comes from the bar width or, when every row shows its count, from the
+// counts.
+func (p *page) histogram() Histogram {
+	rule := p.rules.Histogram
+	if rule.Row == "" {
+		return nil
+	}
+
+	byStars := make(map[int]RatingBar)
+	counted := 0 // rows showing their count
+	p.doc.Find(rule.Row).Each(func(i int, row *goquery.Selection) {
+		stars, err := strconv.Atoi(firstOf(rule.Stars, row))
+		if err != nil || stars < 1 || stars > 5 {
+			return
+		}
+		if _, dup := byStars[stars]; dup {
+			return
+		}
+		bar := RatingBar{Stars: stars}
+		if f, ok := ParseNumber(firstOf(rule.Count, row)); ok {
+			bar.Count = int64(f)
+			counted++
+		}
+		if f, err := strconv.ParseFloat(firstOf(rule.Share, row), 64); err == nil && f >= 0 && f <= 100 {
+			bar.Percent = f
+		}
+		byStars[stars] = bar
+	})
+	if len(byStars) == 0 {
+		return nil
+	}
+
+	h := make(Histogram, 0, len(byStars))
+	var total int64
+	for _, bar := range byStars {
+		h = append(h, bar)
+		total += bar.Count
+	}
+	if counted == 5 && total > 0 {
+		for i := range h {
+			h[i].Percent = math.Round(float64(h[i].Count)*1000/float64(total)) / 10
+		}
+	}
+	sort.Slice(h, func(i, j int) bool { return h[i].Stars > h[j].Stars })
+	return h
+}
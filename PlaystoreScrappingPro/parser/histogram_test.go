@@ -0,0 +1,22 @@
+package parser
+
+import "testing"
+
+func TestHistogram(t *testing.T) {
+	h := Histogram{{Stars: 5, Percent: 72}, {Stars: 4, Percent: 12}, {Stars: 3, Percent: 5}, {Stars: 2, Percent: 3}, {Stars: 1, Percent: 8}}
+
+	if got, want := h.String(), "5★ 72% · 4★ 12% · 3★ 5% · 2★ 3% · 1★ 8%"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if avg, ok := h.Average(); !ok || avg != 4.37 {
+		t.Errorf("Average() = %v, %v, want 4.37", avg, ok)
+	}
+	if got := h.Share(1); got != 8 {
+		t.Errorf("Share(1) = %v, want 8", got)
+	}
+
+	var empty Histogram
+	if _, ok := empty.Average(); ok || empty.String() != "" || empty.Share(5) != 0 {
+		t.Error("empty histogram has values")
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ImageSize is a resolution to ask Google's image server for. A zero side
+// is left to the server (it keeps the aspect ratio).
+type ImageSize struct {
+	Width, Height int
+}
+
+// MaxImageSide is the largest side ParseImageSize accepts
+const MaxImageSide = 4096
+
+// ParseImageSize reads "512" (a square) or "1024x500", where one side may be
+// 0 ("1024x0") to keep the aspect ratio; "" is the zero size
+func ParseImageSize(v string) (ImageSize, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ImageSize{}, nil
+	}
+	w, h, square := strings.Cut(strings.ToLower(v), "x")
+	width, err1 := strconv.Atoi(w)
+	height := width
+	var err2 error
+	if square {
+		height, err2 = strconv.Atoi(h)
+	}
+	if err1 != nil || err2 != nil || width < 0 || height < 0 || width+height == 0 || width > MaxImageSide || height > MaxImageSide {
+		return ImageSize{}, fmt.Errorf("image size must be N or WxH with sides up to %d (one side may be 0), got %q", MaxImageSide, v)
+	}
+	return ImageSize{Width: width, Height: height}, nil
+}
+
+// IsZero reports whether no resolution was asked for
+func (s ImageSize) IsZero() bool {
+	return s.Width == 0 && s.Height == 0
+}
+
+// ResizeImage rewrites a Google image URL (play-lh.googleusercontent.com
+// and friends) to the =wNNN-hNNN variant of size, replacing the size
+// options it had. Other URLs and the zero size return u unchanged.
+func ResizeImage(u string, size ImageSize) string {
+	if size.IsZero() {
+		return u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil || !strings.HasSuffix(parsed.Host, ".googleusercontent.com") {
+		return u
+	}
+
+	// the options follow the last "=" of the last path segment
+	path := parsed.Path
+	if i := strings.LastIndex(path, "="); i > strings.LastIndex(path, "/") {
+		path = path[:i]
+	}
+	var opts []string
+	if size.Width > 0 {
+		opts = append(opts, "w"+strconv.Itoa(size.Width))
+	}
+	if size.Height > 0 {
+		opts = append(opts, "h"+strconv.Itoa(size.Height))
+	}
+	parsed.Path = path + "=" + strings.Join(opts, "-")
+	parsed.RawPath = ""
+	return parsed.String()
+}
+
+// WithImageSizes returns a copy of a whose icon is resized to icon and
+// whose screenshots and feature graphic are resized to images
+func (a *App) WithImageSizes(icon, images ImageSize) *App {
+	out := *a
+	out.Icon = ResizeImage(a.Icon, icon)
+	out.FeatureGraphic = ResizeImage(a.FeatureGraphic, images)
+	out.Screenshots = resizeAll(a.Screenshots, images)
+	out.TabletScreenshots = resizeAll(a.TabletScreenshots, images)
+	return &out
+}
+
+// Images lists every image URL of a: icon, feature graphic, screenshots
+// and tablet screenshots (empty ones left out)
+func (a *App) Images() []string {
+	var out []string
+	for _, u := range append([]string{a.Icon, a.FeatureGraphic}, append(a.Screenshots, a.TabletScreenshots...)...) {
+		if u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func resizeAll(urls []string, size ImageSize) []string {
+	if urls == nil || size.IsZero() {
+		return urls
+	}
+	out := make([]string, len(urls))
+	for i, u := range urls {
+		out[i] = ResizeImage(u, size)
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package parser
+
+import "testing"
+
+func TestResizeImage(t *testing.T) {
+	tests := []struct {
+		url  string
+		size ImageSize
+		want string
+	}{
+		{"https://play-lh.googleusercontent.com/abc", ImageSize{512, 512}, "https://play-lh.googleusercontent.com/abc=w512-h512"},
+		{"https://play-lh.googleusercontent.com/abc=w240-h480", ImageSize{1024, 500}, "https://play-lh.googleusercontent.com/abc=w1024-h500"},
+		{"https://play-lh.googleusercontent.com/abc=s64-rw", ImageSize{Width: 1080}, "https://play-lh.googleusercontent.com/abc=w1080"},
+		{"https://lh3.googleusercontent.com/x/abc=w526", ImageSize{Height: 300}, "https://lh3.googleusercontent.com/x/abc=h300"},
+		{"https://play-lh.googleusercontent.com/abc=w240", ImageSize{}, "https://play-lh.googleusercontent.com/abc=w240"},
+		{"https://example.com/icon.png=w10", ImageSize{512, 512}, "https://example.com/icon.png=w10"},
+		{"", ImageSize{512, 512}, ""},
+	}
+	for _, tt := range tests {
+		if got := ResizeImage(tt.url, tt.size); got != tt.want {
+			t.Errorf("ResizeImage(%q, %v) = %q, want %q", tt.url, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestParseImageSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ImageSize
+		ok   bool
+	}{
+		{"", ImageSize{}, true},
+		{"512", ImageSize{512, 512}, true},
+		{"1024x500", ImageSize{1024, 500}, true},
+		{" 300X200 ", ImageSize{300, 200}, true},
+		{"1024x0", ImageSize{Width: 1024}, true},
+		{"0x500", ImageSize{Height: 500}, true},
+		{"0", ImageSize{}, false},
+		{"0x0", ImageSize{}, false},
+		{"-1x500", ImageSize{}, false},
+		{"10x", ImageSize{}, false},
+		{"5000", ImageSize{}, false},
+		{"big", ImageSize{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseImageSize(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseImageSize(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"context"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// missingChecks lists, per field (json name), how ParsePlayStoreHTML reports
+// that it could not extract a value
+var missingChecks = []struct {
+	field   string
+	missing func(app *App) bool
+}{
+	{"icon", func(a *App) bool { return a.Icon == "" }},
+	{"developer", func(a *App) bool { return a.Developer == "" }},
+	{"genre", func(a *App) bool { return a.Category == "" || a.Category == "N/A" }},
+	{"rating", func(a *App) bool { return a.Rating == "" }},
+	{"ratingCount", func(a *App) bool { return a.RatingCount == "" || a.RatingCount == "0" }},
+	{"installs", func(a *App) bool { return a.Installs == "N.A" || a.Installs == "N/A" }},
+	{"updated", func(a *App) bool { return a.LastUpdated == "" }},
+	{"version", func(a *App) bool { return a.CurrentVersion == "N.A" }},
+	{"androidVersion", func(a *App) bool { return a.AndroidVersion == "N.A" }},
+	{"description", func(a *App) bool { return a.Description == "No description available" }},
+	{"screenshots", func(a *App) bool { return len(a.Screenshots) == 0 }},
+}
+
+// MissingFields returns the json names of fields that fell back to a placeholder
+func MissingFields(app *App) []string {
+	var out []string
+	for _, c := range missingChecks {
+		if c.missing(app) {
+			out = append(out, c.field)
+		}
+	}
+	return out
+}
+
+// ParsePlayStoreHTMLContext is ParsePlayStoreHTML plus a log line, tagged
+// with ctx's request ID, saying how long parsing took and which fields fell
+// back to placeholders
+func ParsePlayStoreHTMLContext(ctx context.Context, doc *goquery.Document) (*App, error) {
+	log := logging.From(ctx)
+	start := time.Now()
+
+	app, err := ParsePlayStoreHTML(doc)
+	if err != nil {
+		log.Warn("parse failed", "error", err, "duration_ms", time.Since(start).Milliseconds())
+		return nil, err
+	}
+
+	missing := MissingFields(app)
+	if len(missing) > 0 {
+		log.Info("parsed with missing fields", "title", app.Title, "missing", missing, "duration_ms", time.Since(start).Milliseconds())
+	} else {
+		log.Debug("parsed", "title", app.Title, "duration_ms", time.Since(start).Milliseconds())
+	}
+	return app, nil
+}
@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Price is what an app costs on the storefront (country) its page was
+// fetched for
+type Price struct {
+	Amount   float64    `json:"amount"`             // 0 = free
+	Currency string     `json:"currency"`           // ISO 4217, "" when the page doesn't tell
+	Display  string     `json:"display"`            // as shown, e.g. ₹49.00
+	Original float64    `json:"original,omitempty"` // the price before a running discount
+	SaleEnds *time.Time `json:"saleEnds,omitempty"` // when that discount ends, if shown
+	Country  string     `json:"country,omitempty"`  // storefront, see App.InCountry
+}
+
+// PriceRange is the span of an app's in-app purchase prices
+type PriceRange struct {
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Currency string  `json:"currency"`
+	Display  string  `json:"display"` // as shown, e.g. "$0.99 - $99.99 per item"
+}
+
+// Discounted reports whether p is a sale price
+func (p *Price) Discounted() bool {
+	return p != nil && p.Original > p.Amount
+}
+
+// String formats p as "Free", "4.99 USD" or "49 INR (was 99 INR until
+// Oct 31, 2026)"; "" for nil
+func (p *Price) String() string {
+	if p == nil {
+		return ""
+	}
+	if p.Amount == 0 {
+		return "Free"
+	}
+	s := formatAmount(p.Amount, p.Currency)
+	if p.Discounted() {
+		s += " (was " + formatAmount(p.Original, p.Currency)
+		if p.SaleEnds != nil {
+			s += " until " + p.SaleEnds.Format("Jan 2, 2006")
+		}
+		s += ")"
+	}
+	return s
+}
+
+// String formats r as "0.99–99.99 USD"; "" for nil
+func (r *PriceRange) String() string {
+	if r == nil {
+		return ""
+	}
+	if r.Min == r.Max {
+		return formatAmount(r.Min, r.Currency)
+	}
+	return strconv.FormatFloat(r.Min, 'f', -1, 64) + "–" + formatAmount(r.Max, r.Currency)
+}
+
+func formatAmount(v float64, currency string) string {
+	return strings.TrimSpace(strconv.FormatFloat(v, 'f', -1, 64) + " " + currency)
+}
+
+// InCountry returns a copy of a whose prices are marked as those of the
+// storefront country (gl, e.g. "US") and, where the page showed an
+// ambiguous symbol ("$", "kr") or none, priced in that country's currency
+func (a *App) InCountry(country string) *App {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	out := *a
+	if a.Price != nil {
+		p := *a.Price
+		p.Country = country
+		if p.Currency == "" {
+			p.Currency = localCurrency(p.Display, country)
+		}
+		out.Price = &p
+	}
+	if a.InAppPrice != nil {
+		r := *a.InAppPrice
+		if r.Currency == "" {
+			r.Currency = localCurrency(r.Display, country)
+		}
+		out.InAppPrice = &r
+	}
+	return &out
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// PARSING — amounts and currencies as the store displays them
+///////////////////////////////////////////////////////////////////////////////
+
+// amountRe matches a number with thousands and decimal separators, which
+// depend on the language: 1,234.56 / 1.234,56 / 1 234,56
+var amountRe = regexp.MustCompile(`\d[\d.,\x{00a0}\x{202f}]*`)
+
+// ParseAmount reads the first price in v: "₹1,500.00", "4,99 €", "Rp 99.000"
+func ParseAmount(v string) (float64, bool) {
+	m := strings.TrimRight(amountRe.FindString(v), ".,\u00a0\u202f")
+	if m == "" {
+		return 0, false
+	}
+	m = strings.NewReplacer("\u00a0", "", "\u202f", "").Replace(m)
+
+	if i := strings.LastIndexAny(m, ".,"); i >= 0 {
+		sep := m[i : i+1]
+		other := ","
+		if sep == "," {
+			other = "."
+		}
+		switch {
+		case strings.Contains(m[:i], other):
+			// both kinds: the last one is the decimal point
+			m = strings.ReplaceAll(m[:i], other, "") + "." + m[i+1:]
+		case strings.Count(m, sep) > 1 || len(m)-i-1 == 3:
+			// one kind, repeated or grouping three digits: thousands
+			m = strings.ReplaceAll(m, sep, "")
+		default:
+			m = m[:i] + "." + m[i+1:]
+		}
+	}
+	f, err := strconv.ParseFloat(m, 64)
+	return f, err == nil
+}
+
+// currencySymbols are matched in order, so "R$" wins over "$"
+var currencySymbols = []struct{ symbol, code string }{
+	{"US$", "USD"}, {"CA$", "CAD"}, {"AU$", "AUD"}, {"A$", "AUD"}, {"NZ$", "NZD"},
+	{"MX$", "MXN"}, {"HK$", "HKD"}, {"NT$", "TWD"}, {"S$", "SGD"}, {"R$", "BRL"},
+	{"₹", "INR"}, {"€", "EUR"}, {"£", "GBP"}, {"₩", "KRW"}, {"₺", "TRY"}, {"₽", "RUB"},
+	{"₱", "PHP"}, {"₫", "VND"}, {"₦", "NGN"}, {"₪", "ILS"}, {"zł", "PLN"}, {"Rp", "IDR"},
+}
+
+// ambiguousSymbols name a different currency per country; the "" entry is
+// the guess when the country doesn't use the symbol
+var ambiguousSymbols = map[string]map[string]string{
+	"$":  {"": "USD", "US": "USD", "CA": "CAD", "AU": "AUD", "NZ": "NZD", "MX": "MXN", "SG": "SGD", "HK": "HKD", "TW": "TWD", "AR": "ARS", "CL": "CLP", "CO": "COP"},
+	"¥":  {"": "JPY", "JP": "JPY", "CN": "CNY"},
+	"kr": {"SE": "SEK", "NO": "NOK", "DK": "DKK", "IS": "ISK"},
+}
+
+// countryCurrencies is each storefront's currency, for prices shown without
+// a symbol
+var countryCurrencies = map[string]string{
+	"US": "USD", "IN": "INR", "GB": "GBP", "CA": "CAD", "AU": "AUD", "NZ": "NZD",
+	"DE": "EUR", "FR": "EUR", "IT": "EUR", "ES": "EUR", "NL": "EUR", "BE": "EUR",
+	"AT": "EUR", "IE": "EUR", "PT": "EUR", "FI": "EUR", "GR": "EUR",
+	"JP": "JPY", "KR": "KRW", "CN": "CNY", "BR": "BRL", "MX": "MXN", "RU": "RUB",
+	"TR": "TRY", "ID": "IDR", "PH": "PHP", "VN": "VND", "NG": "NGN", "IL": "ILS",
+	"PL": "PLN", "SE": "SEK", "NO": "NOK", "DK": "DKK", "CH": "CHF", "SG": "SGD",
+	"HK": "HKD", "TW": "TWD", "ZA": "ZAR", "PK": "PKR", "AR": "ARS", "CL": "CLP",
+	"CO": "COP", "AE": "AED", "SA": "SAR",
+}
+
+var currencyCodeRe = regexp.MustCompile(`\b[A-Z]{3}\b`)
+
+// currencyOf returns the currency v names with an ISO code or an
+// unambiguous symbol, "" otherwise
+func currencyOf(v string) string {
+	if code := currencyCodeRe.FindString(v); code != "" && isCurrency(code) {
+		return code
+	}
+	for _, s := range currencySymbols {
+		if strings.Contains(v, s.symbol) {
+			return s.code
+		}
+	}
+	return ""
+}
+
+// localCurrency resolves the currency of v shown on country's storefront
+func localCurrency(v, country string) string {
+	if code := currencyOf(v); code != "" {
+		return code
+	}
+	for symbol, byCountry := range ambiguousSymbols {
+		if strings.Contains(v, symbol) {
+			if code, ok := byCountry[country]; ok {
+				return code
+			}
+			return byCountry[""]
+		}
+	}
+	return countryCurrencies[country]
+}
+
+func isCurrency(code string) bool {
+	for _, c := range countryCurrencies {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// buildPrices turns the raw price fields of a parse into typed prices; nil
+// when the page shows no price (or no in-app price range)
+func buildPrices(values map[string]string) (*Price, *PriceRange) {
+	var price *Price
+	if display := values["price"]; display != "" {
+		if amount, ok := ParseAmount(display); ok || strings.EqualFold(display, "free") {
+			price = &Price{Amount: amount, Display: display, Currency: currencyOf(display)}
+			if code := strings.ToUpper(strings.TrimSpace(values["priceCurrency"])); isCurrency(code) {
+				price.Currency = code
+			}
+			if original, ok := ParseAmount(values["originalPrice"]); ok && original > amount {
+				price.Original = original
+				if t, ok := ParseDate(values["saleEnds"]); ok {
+					price.SaleEnds = &t
+				}
+			}
+		}
+	}
+
+	var iap *PriceRange
+	if display := values["inAppPriceRange"]; display != "" {
+		for _, m := range amountRe.FindAllString(display, -1) {
+			f, ok := ParseAmount(m)
+			if !ok {
+				continue
+			}
+			if iap == nil {
+				iap = &PriceRange{Min: f, Max: f, Display: display, Currency: currencyOf(display)}
+			}
+			iap.Min, iap.Max = min(iap.Min, f), max(iap.Max, f)
+		}
+	}
+	return price, iap
+}
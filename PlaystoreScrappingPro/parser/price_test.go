@@ -0,0 +1,84 @@
+package parser
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"$4.99", 4.99, true},
+		{"₹1,500.00", 1500, true},
+		{"4,99 €", 4.99, true},
+		{"1.234,56 €", 1234.56, true},
+		{"1\u00a0234,56\u00a0€", 1234.56, true},
+		{"Rp 99.000", 99000, true},
+		{"¥1,200", 1200, true},
+		{"0", 0, true},
+		{"Buy", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseAmount(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseAmount(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestInCountry(t *testing.T) {
+	tests := []struct {
+		display, country, want string
+	}{
+		{"$4.99", "US", "USD"},
+		{"$4.99", "CA", "CAD"},
+		{"$4.99", "DE", "USD"},
+		{"CA$4.99", "US", "CAD"},
+		{"R$ 9,90", "BR", "BRL"},
+		{"29 kr", "SE", "SEK"},
+		{"29 kr", "NO", "NOK"},
+		{"4,99 €", "DE", "EUR"},
+		{"USD 4.99", "IN", "USD"},
+		{"0", "IN", "INR"},
+	}
+	for _, tt := range tests {
+		price, _ := buildPrices(map[string]string{"price": tt.display})
+		app := (&App{Price: price}).InCountry(tt.country)
+		if app.Price.Currency != tt.want || app.Price.Country != tt.country {
+			t.Errorf("%q in %s: currency %q, country %q, want %q", tt.display, tt.country, app.Price.Currency, app.Price.Country, tt.want)
+		}
+	}
+
+	// a currency the page names is kept
+	price, _ := buildPrices(map[string]string{"price": "4.99", "priceCurrency": "eur"})
+	if app := (&App{Price: price}).InCountry("US"); app.Price.Currency != "EUR" {
+		t.Errorf("explicit currency replaced by %q", app.Price.Currency)
+	}
+}
+
+func TestBuildPrices(t *testing.T) {
+	price, iap := buildPrices(map[string]string{
+		"price":           "$1.99",
+		"originalPrice":   "$4.99",
+		"saleEnds":        "Oct 31, 2026",
+		"inAppPriceRange": "$0.99 - $99.99 per item",
+	})
+	if !price.Discounted() || price.Original != 4.99 || price.SaleEnds == nil || price.SaleEnds.Day() != 31 {
+		t.Errorf("price = %+v", price)
+	}
+	if got := price.String(); got != "1.99 (was 4.99 until Oct 31, 2026)" {
+		t.Errorf("String() = %q", got)
+	}
+	if iap == nil || iap.Min != 0.99 || iap.Max != 99.99 {
+		t.Errorf("in-app range = %+v", iap)
+	}
+
+	// an "original" price that isn't higher is no discount
+	price, _ = buildPrices(map[string]string{"price": "Free", "originalPrice": "Free"})
+	if price == nil || price.Amount != 0 || price.Discounted() || price.String() != "Free" {
+		t.Errorf("free price = %+v", price)
+	}
+	if price, iap := buildPrices(map[string]string{}); price != nil || iap != nil {
+		t.Errorf("no price = %+v, %+v", price, iap)
+	}
+}
@@ -0,0 +1,86 @@
+package parser
+
+// Extraction strategies recorded per field, roughly in the order
+// ParsePlayStoreHTML tries them
+const (
+	SourceJSONLD      = "json-ld"         // SoftwareApplication structured data
+	SourceAriaLabel   = "aria-label"      // "Rated 4.5 stars out of five"
+	SourceMeta        = "meta"            // og:/description meta tags, canonical link
+	SourceSelector    = "selector"        // a known element class or attribute
+	SourceDetails     = "details-section" // "About this app" label/value blocks
+	SourceSibling     = "label-sibling"   // "Updated on" + next div
+	SourceAltSelector = "alt-selector"    // newer/alternate installs containers
+	SourceScript      = "script-scan"     // numDownloads-like keys inside <script>
+	SourcePageRegex   = "page-regex"      // "50M+ downloads" anywhere in the page text
+	SourcePageText    = "page-text"       // keyword search over the page text
+	SourcePlaceholder = "placeholder"     // every strategy failed ("N.A", "0", ...)
+)
+
+// FieldSource says which strategy produced a field. Source is empty when
+// every strategy in Tried failed.
+type FieldSource struct {
+	Field  string   `json:"field"`
+	Source string   `json:"source"`
+	Tried  []string `json:"tried"`
+
+	value string
+}
+
+// Diagnostics is the per-field extraction report of one parse
+type Diagnostics struct {
+	Fields  []FieldSource `json:"fields"`
+	Missing []string      `json:"missing"`
+}
+
+// Source returns the strategy that produced field (json name), "" if unknown
+func (d *Diagnostics) Source(field string) string {
+	if d == nil {
+		return ""
+	}
+	for _, f := range d.Fields {
+		if f.Field == field {
+			return f.Source
+		}
+	}
+	return ""
+}
+
+// trace collects FieldSources while ParsePlayStoreHTML runs
+type trace struct {
+	fields []FieldSource
+	index  map[string]int
+}
+
+func newTrace() *trace {
+	return &trace{index: make(map[string]int)}
+}
+
+// record notes that strategy ran for field and left it at value. The
+// strategy becomes the field's source when it changed the value, and the
+// source is cleared when the value was reset to "".
+func (t *trace) record(field, strategy, value string) {
+	i, ok := t.index[field]
+	if !ok {
+		i = len(t.fields)
+		t.index[field] = i
+		t.fields = append(t.fields, FieldSource{Field: field})
+	}
+
+	f := &t.fields[i]
+	f.Tried = append(f.Tried, strategy)
+	if value != f.value {
+		f.value = value
+		f.Source = ""
+		if value != "" {
+			f.Source = strategy
+		}
+	}
+}
+
+func (t *trace) diagnostics(app *App) *Diagnostics {
+	d := &Diagnostics{Fields: t.fields, Missing: MissingFields(app)}
+	if d.Missing == nil {
+		d.Missing = []string{}
+	}
+	return d
+}
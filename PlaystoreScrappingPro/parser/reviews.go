@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Review is one user review from the "all reviews" view of the detail page
+type Review struct {
+	Author  string `json:"author"`
+	Rating  int    `json:"rating"` // stars, 0 if not shown
+	Date    string `json:"date"`
+	Text    string `json:"text"`
+	Helpful int    `json:"helpful"`         // people who found it helpful
+	Reply   *Reply `json:"reply,omitempty"` // the developer's answer
+}
+
+// Reply is a developer's answer to a review
+type Reply struct {
+	Author string `json:"author"`
+	Date   string `json:"date"`
+	Text   string `json:"text"`
+}
+
+// Review markup
+const (
+	reviewBlock   = "div.RHo1pe"
+	reviewAuthor  = "div.X5PpBb"
+	reviewStars   = "div.iXRFPc[aria-label]"
+	reviewDate    = "span.bp9Aid"
+	reviewText    = "div.h3YV2d"
+	reviewHelpful = "div.AJTPZc"
+	replyBlock    = "div.ocpBU"
+	replyAuthor   = "div.I6j64d"
+	replyDate     = "div.I9Jtec"
+	replyText     = "div.ras4vb"
+)
+
+var starsRe = regexp.MustCompile(`(?i)rated\s+(\d)`)
+
+// ParseReviews extracts the reviews shown on the page, in page order
+func ParseReviews(doc *goquery.Document) []Review {
+	reviews := []Review{}
+
+	doc.Find(reviewBlock).Each(func(i int, block *goquery.Selection) {
+		r := Review{
+			Author: textOf(block, reviewAuthor),
+			Date:   textOf(block, reviewDate),
+			Text:   textOf(block, reviewText),
+		}
+		if m := starsRe.FindStringSubmatch(block.Find(reviewStars).First().AttrOr("aria-label", "")); m != nil {
+			r.Rating, _ = strconv.Atoi(m[1])
+		}
+		if n, ok := ParseNumber(textOf(block, reviewHelpful)); ok {
+			r.Helpful = int(n)
+		}
+
+		if reply := block.Find(replyBlock).First(); reply.Length() > 0 {
+			r.Reply = &Reply{
+				Author: textOf(reply, replyAuthor),
+				Date:   textOf(reply, replyDate),
+				Text:   textOf(reply, replyText),
+			}
+		}
+
+		if r.Author != "" || r.Text != "" {
+			reviews = append(reviews, r)
+		}
+	})
+
+	return reviews
+}
+
+// textOf is the collapsed text of the first element matching selector
+func textOf(root *goquery.Selection, selector string) string {
+	return strings.Join(strings.Fields(root.Find(selector).First().Text()), " ")
+}
@@ -0,0 +1,299 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/andybalholm/cascadia"
+)
+
+// Rules are the selectors, label keywords and regexes ParsePlayStoreHTML
+// uses, as ordered strategies per field. The first strategy that yields a
+// value wins. They live in a versioned JSON file so that a Play Store markup
+// change only needs a new rules file, not a release:
+//
+//	PARSER_RULES_PATH=rules.json  load at startup (default: the bundled rules.json)
+//	kill -HUP <pid>               reload it
+//	POST /api/parser/rules/reload reload it
+//
+// A file that fails to load or validate is rejected and the current rules stay
+// in place.
+type Rules struct {
+	Version    int           `json:"version"`
+	JSONLDType string        `json:"jsonldType"` // only ld+json blocks mentioning this type are read
+	Details    DetailsRule   `json:"details"`
+	Histogram  HistogramRule `json:"histogram"`
+	Fields     []FieldRule   `json:"fields"`
+}
+
+// DetailsRule describes the "About this app" label/value blocks read by
+// "details" strategies
+type DetailsRule struct {
+	Block string     `json:"block"`
+	Label []Strategy `json:"label"` // selector strategies, relative to a block
+	Value []Strategy `json:"value"`
+}
+
+// HistogramRule describes the star rating rows read into App.RatingHistogram.
+// Stars, Count and Share are selector strategies relative to a row; Share
+// yields the bar's percentage (e.g. its width).
+type HistogramRule struct {
+	Row   string     `json:"row"`
+	Stars []Strategy `json:"stars"`
+	Count []Strategy `json:"count,omitempty"`
+	Share []Strategy `json:"share,omitempty"`
+}
+
+// FieldRule is the ordered list of strategies for one App field (json name)
+type FieldRule struct {
+	Field      string     `json:"field"`
+	Strategies []Strategy `json:"strategies"`
+}
+
+// Strategy kinds
+const (
+	KindJSONLD       = "jsonld"        // Path in the JSON-LD block
+	KindSelector     = "selector"      // text (or Attr) of the first matching element
+	KindDetails      = "details"       // value of the details block whose label has a Keyword
+	KindScript       = "script"        // Regex over <script> bodies containing a Keyword
+	KindPageRegex    = "page-regex"    // Regex over the whole page text
+	KindPageContains = "page-contains" // bool: page text contains a Keyword
+	KindImages       = "images"        // list: image URLs containing a Keyword, in page order
+	KindField        = "field"         // copy of an earlier Field
+	KindDefault      = "default"       // constant placeholder Value
+)
+
+// Strategy is one way of extracting a field. Which options apply depends on
+// Kind; Regex, when set, keeps only its match (the first non-empty capture
+// group if it has groups).
+type Strategy struct {
+	Kind      string   `json:"kind"`
+	Source    string   `json:"source,omitempty"` // provenance name, defaults per kind
+	Selector  string   `json:"selector,omitempty"`
+	Attr      string   `json:"attr,omitempty"` // read this attribute instead of the text
+	Last      bool     `json:"last,omitempty"` // prefer the last matching element
+	Path      string   `json:"path,omitempty"` // dotted JSON-LD path, e.g. aggregateRating.ratingValue or offers.0.price
+	Keywords  []string `json:"keywords,omitempty"`
+	Exclude   []string `json:"exclude,omitempty"` // skip values containing any of these
+	Regex     string   `json:"regex,omitempty"`
+	Match     string   `json:"match,omitempty"` // bool fields: true when the value matches
+	Collapse  bool     `json:"collapse,omitempty"`
+	Lowercase bool     `json:"lowercase,omitempty"`
+	Limit     int      `json:"limit,omitempty"`
+	Field     string   `json:"field,omitempty"`
+	Value     string   `json:"value,omitempty"`
+
+	re    *regexp.Regexp
+	match *regexp.Regexp
+}
+
+var defaultSources = map[string]string{
+	KindJSONLD:       SourceJSONLD,
+	KindSelector:     SourceSelector,
+	KindDetails:      SourceDetails,
+	KindScript:       SourceScript,
+	KindPageRegex:    SourcePageRegex,
+	KindPageContains: SourcePageText,
+	KindImages:       SourceSelector,
+	KindField:        SourcePlaceholder,
+	KindDefault:      SourcePlaceholder,
+}
+
+//go:embed rules.json
+var defaultRules []byte
+
+var current atomic.Pointer[Rules]
+
+func init() {
+	r, err := ParseRules(defaultRules)
+	if err != nil {
+		panic("parser: bundled rules.json: " + err.Error())
+	}
+	current.Store(r)
+}
+
+// CurrentRules returns the rules ParsePlayStoreHTML is using
+func CurrentRules() *Rules {
+	return current.Load()
+}
+
+// SetRules replaces the rules used by every later parse
+func SetRules(r *Rules) {
+	current.Store(r)
+}
+
+// DefaultRules returns a fresh copy of the bundled rules
+func DefaultRules() *Rules {
+	r, _ := ParseRules(defaultRules)
+	return r
+}
+
+// LoadRulesFile reads and validates a rules file
+func LoadRulesFile(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
+
+// ParseRules decodes and validates rules, compiling their regexes
+func ParseRules(data []byte) (*Rules, error) {
+	var r Rules
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid rules JSON: %v", err)
+	}
+	if err := r.compile(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// fieldKinds is every App field rules may fill, and whether it is a bool
+// (true) or a string/list (false)
+var fieldKinds = map[string]bool{
+	"appName": false, "title": false, "icon": false, "developer": false,
+	"developerEmail": false, "developerWebsite": false, "genre": false,
+	"rating": false, "ratingCount": false, "installs": false,
+	"free": true, "adSupported": true, "InAppPurchase": true,
+	"updated": false, "version": false, "androidVersion": false,
+	"summary": false, "description": false, "screenshots": false,
+	"tabletScreenshots": false, "featureGraphic": false, "video": false,
+	// raw price strings, turned into App.Price and App.InAppPrice
+	"price": false, "priceCurrency": false, "originalPrice": false,
+	"saleEnds": false, "inAppPriceRange": false,
+}
+
+// listFields are filled by "images" strategies, and only by them
+var listFields = map[string]bool{"screenshots": true, "tabletScreenshots": true}
+
+func (r *Rules) compile() error {
+	if r.Version <= 0 {
+		return fmt.Errorf("version must be a positive number")
+	}
+	if _, err := cascadia.Compile(r.Details.Block); r.Details.Block != "" && err != nil {
+		return fmt.Errorf("details.block: %v", err)
+	}
+	for i := range r.Details.Label {
+		if err := r.Details.Label[i].compile(); err != nil {
+			return fmt.Errorf("details.label[%d]: %v", i, err)
+		}
+	}
+	for i := range r.Details.Value {
+		if err := r.Details.Value[i].compile(); err != nil {
+			return fmt.Errorf("details.value[%d]: %v", i, err)
+		}
+	}
+
+	if err := r.Histogram.compile(); err != nil {
+		return fmt.Errorf("histogram.%v", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range r.Fields {
+		f := &r.Fields[i]
+		if _, ok := fieldKinds[f.Field]; !ok {
+			return fmt.Errorf("fields[%d]: unknown field %q", i, f.Field)
+		}
+		if seen[f.Field] {
+			return fmt.Errorf("fields[%d]: %s listed twice", i, f.Field)
+		}
+		seen[f.Field] = true
+
+		for j := range f.Strategies {
+			s := &f.Strategies[j]
+			if err := s.compile(); err != nil {
+				return fmt.Errorf("%s strategy %d: %v", f.Field, j+1, err)
+			}
+			if (s.Kind == KindImages) != listFields[f.Field] {
+				return fmt.Errorf("%s strategy %d: images strategies are only for screenshots and tabletScreenshots", f.Field, j+1)
+			}
+			if s.Kind == KindField && !seen[s.Field] {
+				return fmt.Errorf("%s strategy %d: field %q must be listed earlier", f.Field, j+1, s.Field)
+			}
+		}
+	}
+	if !seen["title"] {
+		return fmt.Errorf("rules for title are required")
+	}
+	return nil
+}
+
+func (h *HistogramRule) compile() error {
+	if h.Row == "" {
+		return nil
+	}
+	if _, err := cascadia.Compile(h.Row); err != nil {
+		return fmt.Errorf("row: %v", err)
+	}
+	if len(h.Stars) == 0 {
+		return fmt.Errorf("stars: a row needs stars strategies")
+	}
+	for name, list := range map[string][]Strategy{"stars": h.Stars, "count": h.Count, "share": h.Share} {
+		for i := range list {
+			if list[i].Kind != KindSelector {
+				return fmt.Errorf("%s[%d]: only selector strategies apply to a row", name, i)
+			}
+			if err := list[i].compile(); err != nil {
+				return fmt.Errorf("%s[%d]: %v", name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Strategy) compile() error {
+	if _, ok := defaultSources[s.Kind]; !ok {
+		return fmt.Errorf("unknown kind %q", s.Kind)
+	}
+	if s.Source == "" {
+		s.Source = defaultSources[s.Kind]
+	}
+
+	switch s.Kind {
+	case KindSelector, KindImages:
+		if s.Selector == "" {
+			return fmt.Errorf("%s needs a selector", s.Kind)
+		}
+		if _, err := cascadia.Compile(s.Selector); err != nil {
+			return fmt.Errorf("selector %q: %v", s.Selector, err)
+		}
+	case KindJSONLD:
+		if s.Path == "" {
+			return fmt.Errorf("jsonld needs a path")
+		}
+	case KindDetails, KindPageContains:
+		if len(s.Keywords) == 0 {
+			return fmt.Errorf("%s needs keywords", s.Kind)
+		}
+	case KindScript, KindPageRegex:
+		if s.Regex == "" {
+			return fmt.Errorf("%s needs a regex", s.Kind)
+		}
+	case KindField:
+		if s.Field == "" {
+			return fmt.Errorf("field needs a field")
+		}
+	}
+
+	var err error
+	if s.Regex != "" {
+		if s.re, err = regexp.Compile(s.Regex); err != nil {
+			return fmt.Errorf("regex: %v", err)
+		}
+	}
+	if s.Match != "" {
+		if s.match, err = regexp.Compile(s.Match); err != nil {
+			return fmt.Errorf("match: %v", err)
+		}
+	}
+	return nil
+}
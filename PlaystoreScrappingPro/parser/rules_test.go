@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRulesRejectsInvalid(t *testing.T) {
+	// rules wraps field strategies into an otherwise minimal rules file
+	rules := func(fields string) string {
+		return `{"version": 1, "fields": [` + fields + `]}`
+	}
+	const title = `{"field": "title", "strategies": [{"kind": "selector", "selector": "h1"}]}`
+
+	tests := []struct {
+		name, rules, err string
+	}{
+		{"not JSON", `{"version": `, "invalid rules JSON"},
+		{"no version", `{"fields": [` + title + `]}`, "version must be a positive number"},
+		{"no title", rules(`{"field": "developer", "strategies": [{"kind": "selector", "selector": "a"}]}`), "rules for title are required"},
+		{"unknown field", rules(title + `, {"field": "colour", "strategies": []}`), `unknown field "colour"`},
+		{"field twice", rules(title + `, ` + title), "title listed twice"},
+		{"unknown kind", rules(`{"field": "title", "strategies": [{"kind": "xpath", "selector": "//h1"}]}`), `unknown kind "xpath"`},
+		{"bad regex", rules(`{"field": "title", "strategies": [{"kind": "script", "keywords": ["x"], "regex": "(unclosed"}]}`), "regex:"},
+		{"bad match", rules(title + `, {"field": "free", "strategies": [{"kind": "selector", "selector": "b", "match": "[z-a]"}]}`), "match:"},
+		{"bad selector", rules(`{"field": "title", "strategies": [{"kind": "selector", "selector": "h1[["}]}`), `selector "h1[["`},
+		{"selector missing", rules(`{"field": "title", "strategies": [{"kind": "selector"}]}`), "selector needs a selector"},
+		{"images on a text field", rules(`{"field": "title", "strategies": [{"kind": "images", "selector": "img"}]}`), "images strategies are only for"},
+		{"field copied before it is set", rules(`{"field": "title", "strategies": [{"kind": "field", "field": "appName"}]}`), `field "appName" must be listed earlier`},
+		{"bad details block", `{"version": 1, "details": {"block": "div[["}, "fields": [` + title + `]}`, "details.block"},
+		{"histogram without stars", `{"version": 1, "histogram": {"row": "div.row"}, "fields": [` + title + `]}`, "histogram.stars"},
+		{"histogram jsonld", `{"version": 1, "histogram": {"row": "div.row", "stars": [{"kind": "jsonld", "path": "x"}]}, "fields": [` + title + `]}`, "only selector strategies"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tt.rules))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseRules = %v, want an error containing %q", err, tt.err)
+			}
+		})
+	}
+
+	if _, err := ParseRules([]byte(rules(title))); err != nil {
+		t.Errorf("minimal rules rejected: %v", err)
+	}
+}
+
+func TestLoadRulesFile(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"version": 2, "fields": [{"field": "title", "strategies": [{"kind": "nope"}]}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadRulesFile(bad); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("LoadRulesFile(bad) = %v, want an error naming the file", err)
+	}
+	if _, err := LoadRulesFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadRulesFile(missing) succeeds")
+	}
+	if r, err := LoadRulesFile("rules.json"); err != nil || r.Version != DefaultRules().Version {
+		t.Errorf("LoadRulesFile(rules.json) = %v, %v, want the bundled rules", r, err)
+	}
+}
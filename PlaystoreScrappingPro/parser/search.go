@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SearchResult is one app card of the store's search results
+type SearchResult struct {
+	Package   string `json:"package"`
+	Title     string `json:"title"`
+	Developer string `json:"developer"`
+	Icon      string `json:"icon"`
+	Rating    string `json:"rating"` // as displayed, "" for apps without one
+}
+
+// Search result markup
+const (
+	searchCard      = "a.Si6A0c[href*='details?id=']"
+	searchIcon      = "img.T75of"
+	searchTitle     = "span.DdYX5"
+	searchDeveloper = "span.wMUdtb"
+	searchRating    = "span.w2kbF"
+)
+
+// ParseSearch extracts the apps of a search results page, in page order. A
+// package listed twice (e.g. once as the featured result) is kept once.
+func ParseSearch(doc *goquery.Document) []SearchResult {
+	results := []SearchResult{}
+	seen := make(map[string]bool)
+
+	doc.Find(searchCard).Each(func(i int, card *goquery.Selection) {
+		href, _ := card.Attr("href")
+		u, err := url.Parse(href)
+		if err != nil {
+			return
+		}
+		pkg := strings.TrimSpace(u.Query().Get("id"))
+		if pkg == "" || seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+
+		results = append(results, SearchResult{
+			Package:   pkg,
+			Title:     textOf(card, searchTitle),
+			Developer: textOf(card, searchDeveloper),
+			Icon:      card.Find(searchIcon).First().AttrOr("src", ""),
+			Rating:    textOf(card, searchRating),
+		})
+	})
+
+	return results
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Helpers to turn Play Store display strings into comparable values
+
+// the suffix must end a word, so "4.1 based on 2K reviews" reads as 4.1, not 4.1e9
+var numberRe = regexp.MustCompile(`(?i)([\d][\d,]*(?:\.\d+)?)\s*(?:([KMB]|cr|lakh)\b)?`)
+
+var numberSuffix = map[string]float64{
+	"k":    1e3,
+	"m":    1e6,
+	"b":    1e9,
+	"lakh": 1e5,
+	"cr":   1e7,
+}
+
+// ParseNumber reads values like "4.5", "1,234", "2.3M", "50M+" or "5 cr+ downloads"
+func ParseNumber(v string) (float64, bool) {
+	m := numberRe.FindStringSubmatch(v)
+	if m == nil {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	if mult, ok := numberSuffix[strings.ToLower(m[2])]; ok {
+		f *= mult
+	}
+	return f, true
+}
+
+var dateLayouts = []string{
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"2006-01-02",
+}
+
+// ParseDate reads the "Updated on" value shown on the detail page
+func ParseDate(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"4.5", 4.5, true},
+		{"1,234", 1234, true},
+		{"2.3M", 2.3e6, true},
+		{"50M+", 50e6, true},
+		{"10K+ downloads", 10e3, true},
+		{"1B+", 1e9, true},
+		{"5 cr+ downloads", 5e7, true},
+		{"2 Lakh+", 2e5, true},
+		{"4.1 based on 2K reviews", 4.1, true},
+		{"3 million", 3, true},
+		{"12 members", 12, true},
+		{"1,000,000+", 1e6, true},
+		{"N.A", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseNumber(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseNumber(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC)
+	for _, in := range []string{"Mar 4, 2026", "March 4, 2026", " 4 Mar 2026 ", "4 March 2026", "2026-03-04"} {
+		if got, ok := ParseDate(in); !ok || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v", in, got, ok)
+		}
+	}
+	if _, ok := ParseDate("Varies with device"); ok {
+		t.Error("ParseDate accepts text")
+	}
+}
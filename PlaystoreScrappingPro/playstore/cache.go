@@ -0,0 +1,64 @@
+package playstore
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache stores answers as JSON, so it can be backed by anything (memory,
+// Redis, a file...). Keys start with "app:", "reviews:" or "search:" and
+// include the locale. Cached apps come back without Diagnostics.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+}
+
+// MemoryCache is an in-process Cache whose entries expire after a TTL
+type MemoryCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty cache keeping entries for ttl
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{ttl: ttl, entries: make(map[string]memoryEntry)}
+}
+
+// Get returns an unexpired entry
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.RLock()
+	e, ok := m.entries[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if now := time.Now(); now.After(e.expires) {
+		m.mu.Lock()
+		if e, ok := m.entries[key]; ok && now.After(e.expires) {
+			delete(m.entries, key)
+		}
+		m.mu.Unlock()
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores value for the cache's TTL
+func (m *MemoryCache) Set(key string, value []byte) {
+	m.mu.Lock()
+	m.entries[key] = memoryEntry{value: value, expires: time.Now().Add(m.ttl)}
+	m.mu.Unlock()
+}
+
+// Len returns the number of entries, expired ones included
+func (m *MemoryCache) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.entries)
+}
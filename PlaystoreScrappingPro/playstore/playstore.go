@@ -0,0 +1,277 @@
+// Package playstore is the scraper as a library: it fetches and parses
+// Google Play pages without the web server, its globals or its storage.
+//
+//	client := playstore.New(
+//		playstore.WithLocale("en_GB", "GB"),
+//		playstore.WithCache(playstore.NewMemoryCache(time.Hour)),
+//	)
+//	app, err := client.App(ctx, "com.whatsapp")
+//
+// The parser's selectors target the English store pages; with another
+// language some text fields (installs, content rating, ...) may come back
+// empty.
+package playstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/time/rate"
+)
+
+// The parsed types, so callers need only this package. App.WithImageSizes
+// asks for other image resolutions.
+type (
+	App          = parser.App
+	Review       = parser.Review
+	SearchResult = parser.SearchResult
+	ImageSize    = parser.ImageSize
+	Price        = parser.Price
+	PriceRange   = parser.PriceRange
+)
+
+// ErrNotFound is returned when Google Play has no such app
+var ErrNotFound = scraper.ErrNotFound
+
+// ErrPageTooLarge is returned for a page over scraper.MaxPageSize
+var ErrPageTooLarge = scraper.ErrPageTooLarge
+
+// ThrottledError is returned when Google Play still answers 429 after the
+// last retry
+type ThrottledError = scraper.ThrottledError
+
+// Defaults of New
+const (
+	DefaultTimeout    = 3 * time.Second
+	DefaultRateLimit  = scraper.DefaultRateLimit // requests per second
+	DefaultRateBurst  = scraper.DefaultRateBurst
+	DefaultAttempts   = 3
+	DefaultRetryDelay = time.Second
+	MaxRetryAfter     = 10 * time.Second // longest 429 Retry-After honoured
+)
+
+// Client fetches and parses store pages. It is safe for concurrent use.
+type Client struct {
+	fetcher    scraper.Fetcher
+	cache      Cache
+	attempts   int
+	retryDelay time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests through hc (default: a client with a 3
+// second timeout)
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.fetcher.HTTPClient = hc
+	}
+}
+
+// WithBaseURL fetches from another store root, e.g. a fake store in tests
+// (default https://play.google.com)
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.fetcher.BaseURL = u
+	}
+}
+
+// WithLocale sets the page language (hl, e.g. "en_US" or "de") and country
+// (gl, e.g. "US" or "DE"); the default is en_US / US. App prices are those
+// of that country's storefront.
+func WithLocale(language, country string) Option {
+	return func(c *Client) {
+		c.fetcher.Language = language
+		c.fetcher.Country = country
+	}
+}
+
+// WithCache keeps answers in cache (default: none, every call fetches)
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithRateLimiter paces outbound requests with l, which may be shared by
+// several clients (default: 2 requests per second, bursts of 4, per
+// client). nil turns pacing off.
+func WithRateLimiter(l *rate.Limiter) Option {
+	return func(c *Client) {
+		if l == nil {
+			l = rate.NewLimiter(rate.Inf, 0)
+		}
+		c.fetcher.Limiter = l
+	}
+}
+
+// WithRetries makes up to attempts tries per page, waiting delay between
+// them (or a 429's Retry-After, up to MaxRetryAfter). A 404 is never
+// retried; attempts below 1 mean 1.
+func WithRetries(attempts int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.attempts = max(attempts, 1)
+		c.retryDelay = delay
+	}
+}
+
+// New returns a client configured by opts
+func New(opts ...Option) *Client {
+	c := &Client{
+		fetcher: scraper.Fetcher{
+			HTTPClient: &http.Client{Timeout: DefaultTimeout},
+			BaseURL:    scraper.DefaultBaseURL,
+			Limiter:    rate.NewLimiter(DefaultRateLimit, DefaultRateBurst),
+		},
+		attempts:   DefaultAttempts,
+		retryDelay: DefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// App fetches and parses the detail page of the package id (e.g.
+// "com.whatsapp")
+func (c *Client) App(ctx context.Context, id string) (*App, error) {
+	id, err := packageName(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var app *App
+	if c.cached("app", id, &app) {
+		return app, nil
+	}
+	doc, err := c.fetch(ctx, id, c.fetcher.AppHTML)
+	if err != nil {
+		return nil, err
+	}
+	if app, err = parser.ParsePlayStoreHTMLContext(ctx, doc); err != nil {
+		return nil, err
+	}
+	country := c.fetcher.Country
+	if country == "" {
+		country = scraper.DefaultCountry
+	}
+	app = app.InCountry(country)
+	c.store("app", id, app)
+	return app, nil
+}
+
+// Reviews fetches the reviews shown on the "all reviews" view of id
+func (c *Client) Reviews(ctx context.Context, id string) ([]Review, error) {
+	id, err := packageName(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []Review
+	if c.cached("reviews", id, &reviews) {
+		return reviews, nil
+	}
+	doc, err := c.fetch(ctx, id, c.fetcher.ReviewsHTML)
+	if err != nil {
+		return nil, err
+	}
+	reviews = parser.ParseReviews(doc)
+	c.store("reviews", id, reviews)
+	return reviews, nil
+}
+
+// Search returns the apps the store lists for query, in page order
+func (c *Client) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+
+	var results []SearchResult
+	if c.cached("search", strings.ToLower(query), &results) {
+		return results, nil
+	}
+	doc, err := c.fetch(ctx, query, c.fetcher.SearchHTML)
+	if err != nil {
+		return nil, err
+	}
+	results = parser.ParseSearch(doc)
+	c.store("search", strings.ToLower(query), results)
+	return results, nil
+}
+
+// packageName checks id looks like a package name
+func packageName(id string) (string, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if !strings.Contains(id, ".") || strings.Trim(id, "abcdefghijklmnopqrstuvwxyz0123456789._") != "" {
+		return "", fmt.Errorf("invalid package name %q (use com.example.app)", id)
+	}
+	return id, nil
+}
+
+// fetch runs get up to c.attempts times; a 404 or an oversized page is final
+func (c *Client) fetch(ctx context.Context, arg string, get func(context.Context, string) (*goquery.Document, error)) (*goquery.Document, error) {
+	log := logging.From(ctx)
+
+	var doc *goquery.Document
+	var err error
+	for attempt := 1; attempt <= c.attempts; attempt++ {
+		doc, err = get(ctx, arg)
+		if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrPageTooLarge) || ctx.Err() != nil || attempt == c.attempts {
+			break
+		}
+		log.Debug("fetch attempt failed", "target", arg, "attempt", attempt, "error", err)
+
+		wait := c.retryDelay
+		var throttled *ThrottledError
+		if errors.As(err, &throttled) && throttled.RetryAfter > wait {
+			wait = min(throttled.RetryAfter, MaxRetryAfter)
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, err
+		case <-t.C:
+		}
+	}
+	return doc, err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// CACHE
+///////////////////////////////////////////////////////////////////////////////
+
+// cacheKey includes the locale: the same app has other texts elsewhere
+func (c *Client) cacheKey(kind, id string) string {
+	return kind + ":" + c.fetcher.Language + ":" + c.fetcher.Country + ":" + id
+}
+
+// cached decodes a cached answer into out
+func (c *Client) cached(kind, id string, out interface{}) bool {
+	if c.cache == nil {
+		return false
+	}
+	data, ok := c.cache.Get(c.cacheKey(kind, id))
+	return ok && json.Unmarshal(data, out) == nil
+}
+
+func (c *Client) store(kind, id string, v interface{}) {
+	if c.cache == nil {
+		return
+	}
+	if data, err := json.Marshal(v); err == nil {
+		c.cache.Set(c.cacheKey(kind, id), data)
+	}
+}
@@ -0,0 +1,168 @@
+package playstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/fakestore"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/playstore"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/scraper"
+)
+
+// newClient returns a client of a fake store, without pacing
+func newClient(t *testing.T, opts ...playstore.Option) (*playstore.Client, *fakestore.Server) {
+	t.Helper()
+	store := fakestore.New(nil)
+	srv := store.Start()
+	t.Cleanup(srv.Close)
+
+	opts = append([]playstore.Option{
+		playstore.WithBaseURL(srv.URL),
+		playstore.WithRateLimiter(nil),
+		playstore.WithRetries(3, time.Millisecond),
+	}, opts...)
+	return playstore.New(opts...), store
+}
+
+func TestClientApp(t *testing.T) {
+	cache := playstore.NewMemoryCache(time.Hour)
+	client, store := newClient(t, playstore.WithCache(cache))
+	ctx := context.Background()
+
+	app, err := client.App(ctx, " com.example.Messenger ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Title != "Example Messenger" || app.Diagnostics == nil {
+		t.Errorf("app = %+v", app)
+	}
+
+	// second call is a cache hit
+	if cached, err := client.App(ctx, "com.example.messenger"); err != nil || cached.Title != app.Title {
+		t.Errorf("cached app = %+v, %v", cached, err)
+	}
+	if store.Requests() != 1 || cache.Len() != 1 {
+		t.Errorf("requests = %d, cache entries = %d, want 1 and 1", store.Requests(), cache.Len())
+	}
+
+	// a 404 is final
+	if _, err := client.App(ctx, "com.example.missing"); !errors.Is(err, playstore.ErrNotFound) {
+		t.Errorf("missing app: err = %v, want ErrNotFound", err)
+	}
+	if store.Requests() != 2 {
+		t.Errorf("requests = %d, want 2 (no retry of a 404)", store.Requests())
+	}
+
+	if _, err := client.App(ctx, "../etc/passwd"); err == nil {
+		t.Error("invalid package: no error")
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	client, store := newClient(t)
+	ctx := context.Background()
+
+	store.FailNext(2, http.StatusServiceUnavailable)
+	if _, err := client.App(ctx, "com.example.notes"); err != nil {
+		t.Errorf("after 2 failures: %v", err)
+	}
+
+	store.Reset()
+	store.FailNext(3, http.StatusTooManyRequests)
+	_, err := client.App(ctx, "com.example.notes")
+	var throttled *playstore.ThrottledError
+	if !errors.As(err, &throttled) || store.Requests() != 3 {
+		t.Errorf("after 3 throttled attempts: err = %v, requests = %d", err, store.Requests())
+	}
+
+	single, store := newClient(t, playstore.WithRetries(1, time.Millisecond))
+	store.FailNext(1, http.StatusServiceUnavailable)
+	if _, err := single.App(ctx, "com.example.notes"); err == nil || store.Requests() != 1 {
+		t.Errorf("one attempt: err = %v, requests = %d", err, store.Requests())
+	}
+}
+
+func TestClientPageTooLarge(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.Write([]byte("<html><body>"))
+		w.Write(bytes.Repeat([]byte("x"), scraper.MaxPageSize))
+	}))
+	defer srv.Close()
+
+	client := playstore.New(
+		playstore.WithBaseURL(srv.URL),
+		playstore.WithHTTPClient(srv.Client()),
+		playstore.WithRateLimiter(nil),
+		playstore.WithRetries(3, time.Millisecond),
+	)
+	_, err := client.App(context.Background(), "com.example.notes")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !errors.Is(err, playstore.ErrPageTooLarge) || requests != 1 {
+		t.Errorf("oversized page: err = %v after %d requests, want ErrPageTooLarge without retries", err, requests)
+	}
+}
+
+func TestClientSearchAndReviews(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	results, err := client.Search(ctx, "  Messenger ")
+	if err != nil || len(results) == 0 || results[0].Package == "" {
+		t.Errorf("Search = %+v, %v", results, err)
+	}
+	if _, err := client.Search(ctx, " "); err == nil {
+		t.Error("empty query: no error")
+	}
+
+	reviews, err := client.Reviews(ctx, "com.example.messenger")
+	if err != nil || len(reviews) == 0 || reviews[0].Author == "" {
+		t.Errorf("Reviews = %+v, %v", reviews, err)
+	}
+}
+
+func TestClientLocale(t *testing.T) {
+	store := fakestore.New(nil)
+	var mu sync.Mutex
+	var query url.Values
+	var language string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		query, language = r.URL.Query(), r.Header.Get("Accept-Language")
+		mu.Unlock()
+		store.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	client := playstore.New(
+		playstore.WithBaseURL(srv.URL),
+		playstore.WithHTTPClient(srv.Client()),
+		playstore.WithLocale("de_AT", "AT"),
+	)
+	app, err := client.App(context.Background(), "com.example.notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Price == nil || app.Price.Country != "AT" || app.Price.Currency != "EUR" {
+		t.Errorf("price = %+v, want the AT storefront's", app.Price)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if query.Get("hl") != "de_AT" || query.Get("gl") != "AT" || language != "de-AT,de;q=0.9" {
+		t.Errorf("hl=%q gl=%q Accept-Language=%q", query.Get("hl"), query.Get("gl"), language)
+	}
+}
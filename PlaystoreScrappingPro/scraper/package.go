@@ -0,0 +1,41 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidatePackage cleans up a package name (trimmed, lower-cased) and rejects
+// anything that isn't letters, digits and dots, so it is safe in a URL or a
+// file name
+func ValidatePackage(pkg string) (string, error) {
+
+	pkg = strings.TrimSpace(pkg)
+	pkg = strings.ToLower(pkg)
+
+	if pkg == "" {
+		return "", fmt.Errorf("package name is required")
+	}
+
+	if len(pkg) > 60 {
+		return "", fmt.Errorf("package name too long")
+	}
+
+	if !strings.Contains(pkg, ".") {
+		return "", fmt.Errorf("invalid package format (use com.example.app)")
+	}
+
+	if strings.ContainsAny(pkg, "/\\?*&=<>'\"{}()[]|;: ") {
+		return "", fmt.Errorf("unsafe characters detected")
+	}
+
+	for _, c := range pkg {
+		if !(c >= 'a' && c <= 'z') &&
+			!(c >= '0' && c <= '9') &&
+			c != '.' {
+			return "", fmt.Errorf("invalid character in package name")
+		}
+	}
+
+	return pkg, nil
+}
@@ -1,12 +1,24 @@
 package scraper
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/metrics"
+
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/time/rate"
 )
 
 // Timeout = 3 seconds (PERFORMANCE NFR)
@@ -14,18 +26,208 @@ var httpClient = &http.Client{
 	Timeout: 3 * time.Second,
 }
 
-func FetchPlayStoreHTML(pkg string) (*goquery.Document, error) {
+// ErrNotFound is returned when Google Play answers 404 for the package
+var ErrNotFound = errors.New("app not found on Play Store")
+
+// MaxPageSize caps how much of a page is read; real detail pages are a
+// fraction of it
+const MaxPageSize = 10 << 20
+
+// ErrPageTooLarge is returned for a page over MaxPageSize (retrying won't help)
+var ErrPageTooLarge = fmt.Errorf("play store page is larger than %d MB", MaxPageSize>>20)
+
+// ThrottledError is returned when Google Play answers 429. RetryAfter is the
+// wait it asked for (0 if it didn't say).
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("play store throttled the request, retry after %s", e.RetryAfter)
+	}
+	return "play store throttled the request"
+}
+
+// DefaultBaseURL is the real store; tests and the fake store (cmd/fakestore)
+// point the scraper elsewhere with SetBaseURL or PLAYSTORE_BASE_URL.
+const DefaultBaseURL = "https://play.google.com"
+
+var baseURL atomic.Value // string
+
+func init() {
+	baseURL.Store(DefaultBaseURL)
+}
+
+// SetBaseURL changes the store root every page is fetched from
+func SetBaseURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("base URL must be an absolute http(s) URL, got %q", u)
+	}
+	baseURL.Store(strings.TrimRight(u, "/"))
+	return nil
+}
+
+// BaseURL returns the store root in use
+func BaseURL() string {
+	return baseURL.Load().(string)
+}
+
+// Outbound rate limit shared by every caller (web requests, batches and the
+// watchlist scheduler) so we never hammer Google Play from one IP.
+const (
+	DefaultRateLimit = 2 // requests per second
+	DefaultRateBurst = 4
+)
+
+var limiter = rate.NewLimiter(DefaultRateLimit, DefaultRateBurst)
+
+// SetRateLimit changes the outbound limit (requests per second, burst size)
+func SetRateLimit(perSecond float64, burst int) {
+	limiter.SetLimit(rate.Limit(perSecond))
+	limiter.SetBurst(burst)
+}
+
+// Fetcher downloads store pages. The zero value uses this package's shared
+// settings (SetBaseURL, SetRateLimit) and the en_US/US locale, as the Fetch
+// functions below do; a library client (package playstore) builds its own.
+type Fetcher struct {
+	HTTPClient *http.Client  // nil = a client with a 3 second timeout
+	BaseURL    string        // "" = BaseURL()
+	Limiter    *rate.Limiter // nil = the shared outbound limiter
+	Language   string        // hl, e.g. en_US or de ("" = en_US)
+	Country    string        // gl, e.g. US or DE ("" = US)
+}
+
+var defaultFetcher = &Fetcher{}
+
+// DefaultCountry is the storefront (gl) used when a Fetcher names none
+const DefaultCountry = "US"
+
+// FetchPlayStoreHTML downloads and parses the detail page of pkg
+func FetchPlayStoreHTML(ctx context.Context, pkg string) (*goquery.Document, error) {
+	return defaultFetcher.AppHTML(ctx, pkg)
+}
+
+// FetchPlayStorePage downloads the raw HTML of pkg's detail page. ctx cancels
+// the wait for a rate-limit slot and the request itself, and carries the
+// request ID into the logs.
+func FetchPlayStorePage(ctx context.Context, pkg string) ([]byte, error) {
+	return defaultFetcher.AppPage(ctx, pkg)
+}
+
+// FetchReviewsHTML downloads and parses the "all reviews" view of pkg
+func FetchReviewsHTML(ctx context.Context, pkg string) (*goquery.Document, error) {
+	return defaultFetcher.ReviewsHTML(ctx, pkg)
+}
+
+// FetchSearchHTML downloads and parses the app search results for query
+func FetchSearchHTML(ctx context.Context, query string) (*goquery.Document, error) {
+	return defaultFetcher.SearchHTML(ctx, query)
+}
+
+// AppHTML downloads and parses the detail page of pkg
+func (f *Fetcher) AppHTML(ctx context.Context, pkg string) (*goquery.Document, error) {
+	return parsePage(f.AppPage(ctx, pkg))
+}
+
+// AppPage downloads the raw HTML of pkg's detail page
+func (f *Fetcher) AppPage(ctx context.Context, pkg string) ([]byte, error) {
+	return f.fetchPage(ctx, pkg, "")
+}
+
+// ReviewsHTML downloads and parses the "all reviews" view of pkg
+func (f *Fetcher) ReviewsHTML(ctx context.Context, pkg string) (*goquery.Document, error) {
+	return parsePage(f.fetchPage(ctx, pkg, "&showAllReviews=true"))
+}
+
+// SearchHTML downloads and parses the app search results for query
+func (f *Fetcher) SearchHTML(ctx context.Context, query string) (*goquery.Document, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+
+	pageURL := fmt.Sprintf(
+		"%s/store/search?q=%s&c=apps&%s",
+		f.baseURL(), url.QueryEscape(query), f.locale(),
+	)
+	return parsePage(f.fetchURL(ctx, pageURL, logging.From(ctx).With("query", query)))
+}
+
+func parsePage(page []byte, err error) (*goquery.Document, error) {
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(page))
+}
+
+// fetchPage downloads pkg's detail page with extra query parameters
+func (f *Fetcher) fetchPage(ctx context.Context, pkg, extra string) ([]byte, error) {
 
 	if !strings.Contains(pkg, ".") {
 		return nil, fmt.Errorf("invalid package name, use format like com.whatsapp")
 	}
 
-	url := fmt.Sprintf(
-		"https://play.google.com/store/apps/details?id=%s&hl=en_US&gl=US",
-		pkg,
+	pageURL := fmt.Sprintf(
+		"%s/store/apps/details?id=%s&%s%s",
+		f.baseURL(), url.QueryEscape(pkg), f.locale(), extra,
 	)
+	return f.fetchURL(ctx, pageURL, logging.From(ctx).With("package", pkg))
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+func (f *Fetcher) baseURL() string {
+	if f.BaseURL != "" {
+		return strings.TrimRight(f.BaseURL, "/")
+	}
+	return BaseURL()
+}
+
+func (f *Fetcher) language() string {
+	if f.Language != "" {
+		return f.Language
+	}
+	return "en_US"
+}
+
+// locale returns the hl and gl query parameters
+func (f *Fetcher) locale() string {
+	country := f.Country
+	if country == "" {
+		country = DefaultCountry
+	}
+	return "hl=" + url.QueryEscape(f.language()) + "&gl=" + url.QueryEscape(country)
+}
+
+// acceptLanguage turns hl into an Accept-Language header ("en_US" ->
+// "en-US,en;q=0.9")
+func (f *Fetcher) acceptLanguage() string {
+	tag := strings.ReplaceAll(f.language(), "_", "-")
+	if base, _, ok := strings.Cut(tag, "-"); ok {
+		return tag + "," + base + ";q=0.9"
+	}
+	return tag
+}
+
+// fetchURL downloads a store page once an outbound slot is free
+func (f *Fetcher) fetchURL(ctx context.Context, pageURL string, log *slog.Logger) ([]byte, error) {
+
+	// RATE LIMIT: wait for an outbound slot
+	lim := f.Limiter
+	if lim == nil {
+		lim = limiter
+	}
+	waitStart := time.Now()
+	if err := lim.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter: %v", err)
+	}
+
+	if waited := time.Since(waitStart); waited > 100*time.Millisecond {
+		log.Debug("rate limited", "wait_ms", waited.Milliseconds())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("request build failed: %v", err)
 	}
@@ -34,20 +236,61 @@ func FetchPlayStoreHTML(pkg string) (*goquery.Document, error) {
 	req.Header.Set("User-Agent",
 		"Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.0.0 Mobile Safari/537.36")
 
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Accept-Language", f.acceptLanguage())
 	req.Header.Set("Accept", "text/html")
 	req.Header.Set("Referer", "https://www.google.com/")
 
 	// PERFORMANCE: Persistent client reused every time
-	res, err := httpClient.Do(req)
+	client := f.HTTPClient
+	if client == nil {
+		client = httpClient
+	}
+	start := time.Now()
+	res, err := client.Do(req)
 	if err != nil {
+		metrics.ObserveUpstream(0, time.Since(start))
+		log.Warn("upstream request failed", "duration_ms", time.Since(start).Milliseconds(), "error", err)
 		return nil, fmt.Errorf("failed to fetch Play Store page: %v", err)
 	}
 	defer res.Body.Close()
+	metrics.ObserveUpstream(res.StatusCode, time.Since(start))
+	log.Debug("upstream response", "status", res.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return nil, &ThrottledError{RetryAfter: retryAfter(res.Header.Get("Retry-After"))}
+	}
 
 	if res.StatusCode != 200 {
 		return nil, fmt.Errorf("play store returned status %d", res.StatusCode)
 	}
 
-	return goquery.NewDocumentFromReader(res.Body)
+	page, err := io.ReadAll(io.LimitReader(res.Body, MaxPageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Play Store page: %v", err)
+	}
+	if len(page) > MaxPageSize {
+		log.Warn("upstream page too large", "limit_bytes", MaxPageSize)
+		return nil, ErrPageTooLarge
+	}
+	return page, nil
+}
+
+// retryAfter parses a Retry-After header (seconds or an HTTP date)
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
 }
@@ -0,0 +1,149 @@
+package selftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser/fixtures"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// The self-test parses every page in parser/fixtures and compares each field
+// with the fixture's golden (json field name -> value). A golden that only
+// has {"error": "..."} means the page must fail to parse with that message.
+
+// FieldResult is the outcome for one expected field
+type FieldResult struct {
+	Field    string      `json:"field"`
+	OK       bool        `json:"ok"`
+	Source   string      `json:"source,omitempty"` // strategy that produced Got
+	Expected interface{} `json:"expected"`
+	Got      interface{} `json:"got"`
+}
+
+// FixtureResult is the outcome for one fixture page
+type FixtureResult struct {
+	Name   string        `json:"name"`
+	OK     bool          `json:"ok"`
+	Error  string        `json:"error,omitempty"`
+	Fields []FieldResult `json:"fields,omitempty"`
+}
+
+// Report is the full self-test result
+type Report struct {
+	OK         bool            `json:"ok"`
+	Passed     int             `json:"passed"`
+	Failed     int             `json:"failed"`
+	DurationMS int64           `json:"durationMs"`
+	Fixtures   []FixtureResult `json:"fixtures"`
+}
+
+// Run parses every bundled fixture and compares the result to its expectations
+func Run() Report {
+	start := time.Now()
+	report := Report{OK: true}
+
+	for _, name := range fixtures.Names() {
+		res := runFixture(name)
+		if res.OK {
+			report.Passed++
+		} else {
+			report.Failed++
+			report.OK = false
+		}
+		report.Fixtures = append(report.Fixtures, res)
+	}
+
+	report.DurationMS = time.Since(start).Milliseconds()
+	return report
+}
+
+func runFixture(name string) FixtureResult {
+	res := FixtureResult{Name: name}
+
+	page, err := fixtures.Page(name)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	rawExpected, err := fixtures.Golden(name)
+	if err != nil {
+		res.Error = fmt.Sprintf("missing golden: %v", err)
+		return res
+	}
+
+	var expected map[string]interface{}
+	if err := json.Unmarshal(rawExpected, &expected); err != nil {
+		res.Error = fmt.Sprintf("invalid golden: %v", err)
+		return res
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	app, parseErr := parser.ParsePlayStoreHTML(doc)
+
+	// negative fixture: the parser must refuse the page
+	if wantErr, ok := expected["error"].(string); ok {
+		switch {
+		case parseErr == nil:
+			res.Error = fmt.Sprintf("expected error %q, parsed %q", wantErr, app.Title)
+		case parseErr.Error() != wantErr:
+			res.Error = fmt.Sprintf("expected error %q, got %q", wantErr, parseErr)
+		default:
+			res.OK = true
+		}
+		return res
+	}
+
+	if parseErr != nil {
+		res.Error = parseErr.Error()
+		return res
+	}
+
+	got, err := toMap(app)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	res.OK = true
+	fields := make([]string, 0, len(expected))
+	for f := range expected {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	for _, f := range fields {
+		fr := FieldResult{Field: f, Expected: expected[f], Got: got[f], Source: app.Diagnostics.Source(f)}
+		fr.OK = equalJSON(fr.Expected, fr.Got)
+		if !fr.OK {
+			res.OK = false
+		}
+		res.Fields = append(res.Fields, fr)
+	}
+	return res
+}
+
+func toMap(app *parser.App) (map[string]interface{}, error) {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	return m, json.Unmarshal(data, &m)
+}
+
+func equalJSON(a, b interface{}) bool {
+	ja, err1 := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ja, jb)
+}
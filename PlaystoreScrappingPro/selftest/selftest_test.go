@@ -0,0 +1,26 @@
+package selftest
+
+import (
+	"testing"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser/fixtures"
+)
+
+func TestRunFixtures(t *testing.T) {
+	report := Run()
+
+	if n := len(fixtures.Names()); n == 0 || report.Passed != n || report.Failed != 0 || !report.OK {
+		for _, f := range report.Fixtures {
+			if !f.OK {
+				t.Errorf("%s: %s %+v", f.Name, f.Error, f.Fields)
+			}
+		}
+		t.Fatalf("passed %d, failed %d of %d fixtures", report.Passed, report.Failed, n)
+	}
+}
+
+func TestRunMissingFixture(t *testing.T) {
+	if res := runFixture("no_such_fixture"); res.OK || res.Error == "" {
+		t.Errorf("missing fixture: %+v, want an error", res)
+	}
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultPath is used when PLAYSTORE_DB_PATH is not set
+const DefaultPath = "data/playstore.db"
+
+// DB is the embedded database shared by every persistent subsystem
+// (history, watchlist, ...); each one owns its own top-level bucket.
+type DB struct {
+	*bolt.DB
+}
+
+// Open opens (or creates) the database file, creating its directory if needed
+func Open(path string) (*DB, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create database directory: %v", err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open database %s: %v", path, err)
+	}
+
+	return &DB{db}, nil
+}
+
+// PathFromEnv returns PLAYSTORE_DB_PATH or DefaultPath
+func PathFromEnv() string {
+	if p := os.Getenv("PLAYSTORE_DB_PATH"); p != "" {
+		return p
+	}
+	return DefaultPath
+}
+
+// EnsureBuckets creates the given top-level buckets if they don't exist
+func (db *DB) EnsureBuckets(names ...string) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range names {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return fmt.Errorf("create bucket %s: %v", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Ping checks the database file can still be read
+func (db *DB) Ping() error {
+	return db.View(func(tx *bolt.Tx) error { return nil })
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/jobs"
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/parser"
+
+	"github.com/gin-gonic/gin"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+// PROGRESS STREAMS — Server-Sent Events for batches and jobs
+///////////////////////////////////////////////////////////////////////////////
+
+// Events, in order:
+//
+//	start    the batch or job about to be streamed
+//	package  one per package as it is fetched and parsed (or fails)
+//	ping     keep-alive while a job waits in the queue
+//	summary  the counts once everything is done
+//
+// Packages arrive in completion order; "index" is their position in the
+// submitted list.
+
+// heartbeat is how often an idle job stream sends a ping
+var heartbeat = 15 * time.Second
+
+// progressEvent is the data of a "package" event
+type progressEvent struct {
+	Index   int         `json:"index"`
+	Package string      `json:"package"`
+	Status  string      `json:"status"` // done, failed or cancelled
+	App     *parser.App `json:"app,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// batchSummary is the data of a batch's "summary" event
+type batchSummary struct {
+	Total      int   `json:"total"`
+	Succeeded  int   `json:"succeeded"`
+	Failed     int   `json:"failed"`
+	DurationMS int64 `json:"durationMs"`
+}
+
+// startStream sets the headers of an event stream; nothing may be written
+// before (errors must be sent as plain JSON first)
+func startStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // keep nginx from buffering events
+	c.Status(http.StatusOK)
+}
+
+// apiBatchStream handles
+//
+//	GET  /api/batch/stream?packages=com.a,com.b
+//	POST /api/batch/stream  {"packages": ["com.a", "com.b"]}
+//
+// like /api/batch, but streams each result as soon as it is known
+func apiBatchStream(c *gin.Context) {
+	pkgs, err := batchPackages(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !chargePackages(c, pkgs, nil) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	start := time.Now()
+	events := make(chan progressEvent)
+	go func() {
+		defer close(events)
+		runBatchEach(ctx, pkgs, func(i int, r BatchResult) {
+			ev := progressEvent{Index: i, Package: r.Package, Status: jobs.ItemDone, App: r.App}
+			if r.Error != "" {
+				ev.Status, ev.Error = jobs.ItemFailed, r.Error
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	startStream(c)
+	c.SSEvent("start", gin.H{"total": len(pkgs), "packages": pkgs})
+
+	summary := batchSummary{Total: len(pkgs)}
+	c.Stream(func(w io.Writer) bool {
+		ev, ok := <-events
+		if !ok {
+			summary.DurationMS = time.Since(start).Milliseconds()
+			c.SSEvent("summary", summary)
+			return false
+		}
+
+		if ev.Status == jobs.ItemDone {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+		c.SSEvent("package", ev)
+		return true
+	})
+}
+
+// apiJobEvents handles GET /api/jobs/:id/events: the packages finished so far,
+// then the rest as the job runs, then a "summary" with the final job. A job
+// that has already finished is replayed in full.
+func apiJobEvents(c *gin.Context) {
+	job, ok := findJob(c)
+	if !ok {
+		return
+	}
+
+	// subscribe before reading the current state so nothing falls in between
+	progress, stop := jobManager.Subscribe(job.ID)
+	defer stop()
+
+	sent := make(map[int]bool)
+	replay := func() bool {
+		items, err := jobManager.Store.Items(job.ID, true)
+		if err != nil {
+			c.SSEvent("error", gin.H{"error": err.Error()})
+			return false
+		}
+		for i, it := range items {
+			if it.Status != jobs.ItemPending && !sent[i] {
+				sent[i] = true
+				c.SSEvent("package", jobEvent(i, it))
+			}
+		}
+		return true
+	}
+
+	job, err := jobManager.Store.Get(job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	startStream(c)
+	c.SSEvent("start", newJobStatus(job))
+	if !replay() {
+		return
+	}
+	if job.Finished() {
+		c.SSEvent("summary", newJobStatus(job))
+		return
+	}
+
+	ping := time.NewTicker(heartbeat)
+	defer ping.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-ping.C:
+			c.SSEvent("ping", gin.H{"time": time.Now().UTC()})
+			return true
+		case p := <-progress:
+			if p.Item != nil {
+				if !sent[p.Index] {
+					sent[p.Index] = true
+					c.SSEvent("package", jobEvent(p.Index, *p.Item))
+				}
+				return true
+			}
+
+			// finished: send whatever a lagging subscription dropped
+			// (including packages the cancellation skipped), then the summary
+			if replay() {
+				c.SSEvent("summary", newJobStatus(p.Job))
+			}
+			return false
+		}
+	})
+}
+
+func jobEvent(i int, it jobs.Item) progressEvent {
+	return progressEvent{Index: i, Package: it.Package, Status: it.Status, App: it.App, Error: it.Error}
+}
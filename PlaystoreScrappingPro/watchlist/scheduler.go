@@ -0,0 +1,120 @@
+package watchlist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/logging"
+)
+
+// Scheduler re-fetches every watched package once per Interval. Each run is
+// shifted by a random offset in [-Jitter, +Jitter] so ~300 packages don't all
+// come due at the same moment. Refreshes run one at a time; Refresh is
+// expected to go through the scraper's outbound rate limiter.
+type Scheduler struct {
+	Store    *Store
+	Interval time.Duration
+	Jitter   time.Duration
+	Refresh  func(ctx context.Context, pkg string) error
+
+	// Tick is how often the watchlist is scanned for due packages
+	Tick time.Duration
+}
+
+// Validate rejects settings Run can't keep to: Interval must be positive and
+// Jitter in [0, Interval), so a run never comes due before the one it follows
+func (s *Scheduler) Validate() error {
+	if s.Interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", s.Interval)
+	}
+	if s.Jitter < 0 || s.Jitter >= s.Interval {
+		return fmt.Errorf("watch jitter must be at least 0 and less than the interval (%s), got %s", s.Interval, s.Jitter)
+	}
+	return nil
+}
+
+// Run blocks until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	tick := s.Tick
+	if tick <= 0 {
+		tick = time.Minute
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	next := make(map[string]time.Time) // package -> next due time
+
+	for {
+		s.runDue(ctx, next)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context, next map[string]time.Time) {
+	entries, err := s.Store.List()
+	if err != nil {
+		slog.Error("watchlist scan failed", "error", err)
+		return
+	}
+
+	watched := make(map[string]bool, len(entries))
+	now := time.Now()
+
+	for _, e := range entries {
+		watched[e.Package] = true
+
+		due, ok := next[e.Package]
+		if !ok {
+			// first sight: schedule from the last refresh (never checked = now)
+			due = now
+			if !e.LastChecked.IsZero() {
+				due = s.nextRun(e.LastChecked)
+			}
+			next[e.Package] = due
+		}
+		if now.Before(due) {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// every refresh gets its own ID so its scraper/parser logs correlate
+		rctx := logging.WithRequestID(ctx, "watch-"+logging.NewID())
+		err := s.Refresh(rctx, e.Package)
+		if err != nil {
+			logging.From(rctx).Warn("watchlist refresh failed", "package", e.Package, "error", err)
+		}
+
+		checked := time.Now()
+		if err := s.Store.MarkChecked(e.Package, checked, err); err != nil {
+			slog.Error("watchlist update failed", "package", e.Package, "error", err)
+		}
+		next[e.Package] = s.nextRun(checked)
+	}
+
+	// forget packages removed from the watchlist
+	for pkg := range next {
+		if !watched[pkg] {
+			delete(next, pkg)
+		}
+	}
+}
+
+func (s *Scheduler) nextRun(from time.Time) time.Time {
+	offset := time.Duration(0)
+	if s.Jitter > 0 {
+		offset = time.Duration(rand.Int63n(int64(2*s.Jitter))) - s.Jitter
+	}
+	return from.Add(s.Interval + offset)
+}
@@ -0,0 +1,145 @@
+package watchlist
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		interval, jitter time.Duration
+		ok               bool
+	}{
+		{6 * time.Hour, 10 * time.Minute, true},
+		{time.Hour, 0, true},
+		{0, 0, false},
+		{-time.Hour, 0, false},
+		{time.Hour, -time.Minute, false},
+		{time.Hour, time.Hour, false},
+	}
+	for _, tt := range tests {
+		s := &Scheduler{Interval: tt.interval, Jitter: tt.jitter}
+		if err := s.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(interval %s, jitter %s) = %v, want ok=%v", tt.interval, tt.jitter, err, tt.ok)
+		}
+	}
+}
+
+func TestNextRunJitter(t *testing.T) {
+	from := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	s := &Scheduler{Interval: time.Hour}
+	if got := s.nextRun(from); !got.Equal(from.Add(time.Hour)) {
+		t.Errorf("no jitter: next run %s, want %s", got, from.Add(time.Hour))
+	}
+
+	s.Jitter = 10 * time.Minute
+	earliest, latest := from.Add(50*time.Minute), from.Add(70*time.Minute)
+	spread := make(map[time.Time]bool)
+	for range 200 {
+		got := s.nextRun(from)
+		if got.Before(earliest) || !got.Before(latest) {
+			t.Fatalf("next run %s outside [%s, %s)", got, earliest, latest)
+		}
+		spread[got] = true
+	}
+	if len(spread) < 2 {
+		t.Error("jitter doesn't spread the runs")
+	}
+}
+
+func TestSchedulerRefreshesDuePackages(t *testing.T) {
+	s := newStore(t)
+	for _, pkg := range []string{"com.example.due", "com.example.fresh"} {
+		if _, err := s.Add(pkg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// checked a minute ago: not due for another hour
+	if err := s.MarkChecked("com.example.fresh", time.Now().Add(-time.Minute), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu        sync.Mutex
+		refreshed []string
+	)
+	done := make(chan struct{})
+	sched := &Scheduler{
+		Store:    s,
+		Interval: time.Hour,
+		Tick:     10 * time.Millisecond,
+		Refresh: func(ctx context.Context, pkg string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			refreshed = append(refreshed, pkg)
+			if len(refreshed) == 1 {
+				close(done)
+			}
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		sched.Run(ctx)
+		close(stopped)
+	}()
+
+	<-done
+	time.Sleep(50 * time.Millisecond) // a few more ticks
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(refreshed) != 1 || refreshed[0] != "com.example.due" {
+		t.Errorf("refreshed %v, want only com.example.due, once", refreshed)
+	}
+	entries, _ := s.List()
+	if entries[0].LastChecked.IsZero() {
+		t.Errorf("refresh of %s not recorded", entries[0].Package)
+	}
+}
+
+func TestSchedulerStopsMidRun(t *testing.T) {
+	s := newStore(t)
+	for _, pkg := range []string{"com.example.a", "com.example.b", "com.example.c"} {
+		if _, err := s.Add(pkg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	sched := &Scheduler{
+		Store:    s,
+		Interval: time.Hour,
+		Refresh: func(ctx context.Context, pkg string) error {
+			calls++
+			cancel() // shutting down while the first refresh runs
+			return ctx.Err()
+		},
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		sched.Run(ctx)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	if calls != 1 {
+		t.Errorf("%d refreshes, want the run to stop after the first", calls)
+	}
+}
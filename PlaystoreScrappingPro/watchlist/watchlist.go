@@ -0,0 +1,114 @@
+package watchlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Layout: bucket "watchlist" -> key = package name, value = JSON Entry
+const bucketName = "watchlist"
+
+// Entry is one watched package and the outcome of its last refresh
+type Entry struct {
+	Package     string    `json:"package"`
+	AddedAt     time.Time `json:"addedAt"`
+	LastChecked time.Time `json:"lastChecked,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// Store persists the watchlist
+type Store struct {
+	db *storage.DB
+}
+
+// NewStore prepares the watchlist bucket in db
+func NewStore(db *storage.DB) (*Store, error) {
+	if err := db.EnsureBuckets(bucketName); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Add starts watching pkg (no-op if it is already watched)
+func (s *Store) Add(pkg string) (Entry, error) {
+	entry := Entry{Package: pkg, AddedAt: time.Now().UTC()}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if v := b.Get([]byte(pkg)); v != nil {
+			return json.Unmarshal(v, &entry)
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(pkg), data)
+	})
+
+	return entry, err
+}
+
+// Remove stops watching pkg, reporting whether it was watched
+func (s *Store) Remove(pkg string) (bool, error) {
+	found := false
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		found = b.Get([]byte(pkg)) != nil
+		return b.Delete([]byte(pkg))
+	})
+
+	return found, err
+}
+
+// List returns every watched package sorted by name
+func (s *Store) List() ([]Entry, error) {
+	entries := []Entry{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucketName)).ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return fmt.Errorf("corrupt watchlist entry %s: %v", k, err)
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Package < entries[j].Package })
+	return entries, err
+}
+
+// MarkChecked records the outcome of a refresh (ignored if pkg was removed meanwhile)
+func (s *Store) MarkChecked(pkg string, at time.Time, refreshErr error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		v := b.Get([]byte(pkg))
+		if v == nil {
+			return nil
+		}
+
+		var e Entry
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+		e.LastChecked = at.UTC()
+		e.LastError = ""
+		if refreshErr != nil {
+			e.LastError = refreshErr.Error()
+		}
+
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(pkg), data)
+	})
+}
@@ -0,0 +1,100 @@
+package watchlist
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dev-suryanshrajawat/Play-Store-Scrapping-Project/PlaystoreScrappingPro/storage"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newStore(t *testing.T) *Store {
+	return openStore(t, filepath.Join(t.TempDir(), "test.db"))
+}
+
+func TestAddRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s := openStore(t, path)
+
+	first, err := s.Add("com.example.notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// adding again keeps the original entry
+	again, err := s.Add("com.example.notes")
+	if err != nil || !again.AddedAt.Equal(first.AddedAt) {
+		t.Errorf("second Add = %+v, %v, want the first entry back", again, err)
+	}
+	if _, err := s.Add("com.example.messenger"); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := s.Remove("com.example.messenger"); !found || err != nil {
+		t.Errorf("Remove watched = %v, %v", found, err)
+	}
+	if found, err := s.Remove("com.example.messenger"); found || err != nil {
+		t.Errorf("Remove unwatched = %v, %v", found, err)
+	}
+
+	// the list survives a restart
+	s.db.Close()
+	s = openStore(t, path)
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Package != "com.example.notes" || !entries[0].AddedAt.Equal(first.AddedAt) {
+		t.Errorf("List after reopening = %+v, want only com.example.notes", entries)
+	}
+}
+
+func TestMarkChecked(t *testing.T) {
+	s := newStore(t)
+	at := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := s.Add("com.example.notes"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.MarkChecked("com.example.notes", at, errors.New("throttled")); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := s.List()
+	if e := entries[0]; !e.LastChecked.Equal(at) || e.LastError != "throttled" {
+		t.Errorf("after a failed refresh: %+v", e)
+	}
+
+	// a later success clears the error
+	if err := s.MarkChecked("com.example.notes", at.Add(time.Hour), nil); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = s.List()
+	if e := entries[0]; !e.LastChecked.Equal(at.Add(time.Hour)) || e.LastError != "" {
+		t.Errorf("after a successful refresh: %+v", e)
+	}
+
+	// a package removed while it was refreshed is not brought back
+	if _, err := s.Remove("com.example.notes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MarkChecked("com.example.notes", at, nil); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := s.List(); len(entries) != 0 {
+		t.Errorf("MarkChecked re-added a removed package: %+v", entries)
+	}
+}